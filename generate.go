@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"mcp-server/pkg/openapi"
+)
+
+// runGenerate generates tool definition files from an OpenAPI document.
+func runGenerate(args []string) int {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	specPath := flags.String("spec", "", "Path to the OpenAPI 3 document in YAML or JSON")
+	outDir := flags.String("out", "resources/tools", "Directory to write the tool definition files to")
+	endpoint := flags.String("endpoint", "", "Backend endpoint overriding the server URL of the document")
+	naturalNames := flags.Bool("natural-names", false, "Name input properties after their parameters using the x-mcp-in extension instead of name prefixes")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *specPath == "" {
+		logger.Error("OpenAPI document path is required")
+		flags.Usage()
+		return 2
+	}
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		logger.Error("Failed to read OpenAPI document", "error", err)
+		return 1
+	}
+	tools, err := openapi.Generate(data, openapi.Options{Endpoint: *endpoint, NaturalNames: *naturalNames})
+	if err != nil {
+		logger.Error("Failed to generate tools", "error", err)
+		return 1
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		logger.Error("Failed to create output directory", "error", err)
+		return 1
+	}
+	for _, tool := range tools {
+		content, err := json.MarshalIndent(tool, "", "    ")
+		if err != nil {
+			logger.Error("Failed to marshal tool definition", "tool", tool.Name, "error", err)
+			return 1
+		}
+		file := filepath.Join(*outDir, tool.Name+".json")
+		if err := os.WriteFile(file, append(content, '\n'), 0o644); err != nil {
+			logger.Error("Failed to write tool definition", "file", file, "error", err)
+			return 1
+		}
+		logger.Info("Generated tool definition", "tool", tool.Name, "file", file)
+	}
+	return 0
+}
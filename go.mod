@@ -4,7 +4,10 @@ go 1.23.8
 
 require (
 	github.com/gin-gonic/gin v1.10.0
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/speakeasy-api/jsonpath v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -19,6 +22,7 @@ require (
 	github.com/goccy/go-json v0.10.5 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
+	github.com/kr/text v0.2.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
@@ -31,5 +35,4 @@ require (
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,6 +18,10 @@ import (
 
 var logger = service.GetLogger()
 
+var version = "0.1"
+
+var mcpServer *mcp.Server
+
 func serveRequest(c *gin.Context) {
 	var mcpRequest mcp.MCPRequest
 
@@ -20,60 +29,17 @@ func serveRequest(c *gin.Context) {
 		logger.Error("Failed to bind JSON", "error", err)
 		return
 	}
-	// Validate the request
-	if mcpRequest.ToolName == "" {
-		logger.Error("Tool name is required")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Tool name is required"})
-		return
-	} else if mcpRequest.Arguments == "" {
-		logger.Error("Arguments are required")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Arguments are required"})
+	// Requests may only carry the tool name and arguments of a registered tool
+	mcpServer.Registry().Resolve(&mcpRequest)
+	if err := mcp.ValidateMCPRequest(&mcpRequest); err != nil {
+		logger.Error("Invalid MCP request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
-	} else if mcpRequest.Schema == "" {
-		logger.Warn("Input schema is not provided")
-	}
-	if mcpRequest.IsProxy {
-		if mcpRequest.API.APIName == "" {
-			logger.Warn("API name is not proided")
-		} else if mcpRequest.API.Endpoint == "" {
-			logger.Error("API endpoint is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "API endpoint is required"})
-			return
-		} else if mcpRequest.API.Context == "" {
-			logger.Error("API context is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "API context is required"})
-			return
-		} else if mcpRequest.API.Version == "" {
-			logger.Error("API version is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "API version is required"})
-			return
-		} else if mcpRequest.API.Path == "" {
-			logger.Error("Resource path is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Resource path is required"})
-			return
-		} else if mcpRequest.API.Verb == "" {
-			logger.Error("HTTP verb is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "HTTP verb is required"})
-			return
-		}
-	} else {
-		if mcpRequest.Backend.Endpoint == "" {
-			logger.Error("Backend endpoint is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Backend endpoint is required"})
-			return
-		} else if mcpRequest.Backend.Target == "" {
-			logger.Error("Backend target is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Backend target is required"})
-			return
-		} else if mcpRequest.Backend.Verb == "" {
-			logger.Error("Backend verb is required")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Backend verb is required"})
-			return
-		}
 	}
 
 	// Set logging context
-	ctx := context.Background()
+	// Client disconnects cancel the call to the underlying API
+	ctx := c.Request.Context()
 
 	ctx = context.WithValue(ctx, service.ToolNameKey, mcpRequest.ToolName)
 	if mcpRequest.API.APIName != "" {
@@ -84,27 +50,100 @@ func serveRequest(c *gin.Context) {
 		logger.WarnContext(ctx, "Authentication is not provided for the underlying API. Assuming no authentication is required.")
 	}
 
+	// The output mode of the route can be overridden per request
+	outputMode := c.DefaultQuery("output", service.GetConfig().Server.OutputMode)
+	if outputMode != service.OutputModeRaw && outputMode != service.OutputModeMCP {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported output mode: %s", outputMode)})
+		return
+	}
+
 	// Call the underlying API
 	logger.Info("Calling underlying API", "tool_name", mcpRequest.ToolName)
-	resp, code, err := mcp.CallUnderlyingAPI(ctx, &mcpRequest)
+	resp, err := mcp.CallAPI(ctx, &mcpRequest)
 	if err != nil {
 		logger.ErrorContext(ctx, "Failed to call underlying API", "error", err)
-		c.JSON(code, gin.H{"error": "Failed to call underlying API", "details": err.Error()})
+		toolErr := mcp.NewToolError(err)
+		if toolErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(toolErr.RetryAfter))
+		}
+		if outputMode == service.OutputModeMCP {
+			c.JSON(toolErr.StatusCode(), toolErr.Result())
+			return
+		}
+		c.JSON(toolErr.StatusCode(), toolErr)
+		return
+	}
+	if outputMode == service.OutputModeMCP {
+		c.JSON(resp.StatusCode, mcp.NewCallToolResult(resp))
+		return
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.JSON(resp.StatusCode, mcp.NewBackendError(resp))
 		return
 	}
-	c.SecureJSON(code, resp)
+	if resp.Truncated {
+		c.Header(mcp.HeaderResponseTruncated, "true")
+	}
+	if resp.HasMore && resp.NextPage != "" {
+		c.Header(mcp.HeaderNextPage, resp.NextPage)
+	}
+	c.SecureJSON(resp.StatusCode, resp.Text)
+}
+
+// getCircuitBreakers returns the state of the circuit breakers of the backend hosts.
+func getCircuitBreakers(c *gin.Context) {
+	states := mcp.CircuitBreakerStates()
+	c.IndentedJSON(http.StatusOK, gin.H{"enabled": states != nil, "breakers": states})
 }
 
 func main() {
-	router := service.GetRouter()
-	router.POST("/mcp", serveRequest)
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		os.Exit(runGenerate(os.Args[2:]))
+	}
+
+	transport := flag.String("transport", "http", "Transport used to serve MCP clients: http or stdio")
+	configPath := flag.String("config", "config.toml", "Path to the config file")
+	flag.Parse()
+
+	if *transport == "stdio" {
+		// stdout is reserved for JSON-RPC messages in stdio mode
+		service.SetLogOutput(os.Stderr)
+	}
+	service.SetConfigPath(*configPath)
 	cfg, err := service.InitConfig()
 	if err != nil {
 		logger.Error("Failed to get configurations", "error", err)
 		return
 	}
+	registry := mcp.NewToolRegistry()
+	if cfg.Tools.Directory != "" {
+		registry, err = mcp.LoadToolRegistry(cfg.Tools.Directory)
+		if err != nil {
+			logger.Error("Failed to load tool definitions", "error", err)
+			return
+		}
+	}
+	mcpServer = mcp.NewServer("mcp-transformation-service", version, registry)
+
+	switch *transport {
+	case "http":
+		serveHTTP(cfg)
+	case "stdio":
+		serveStdio()
+	default:
+		logger.Error("Unsupported transport", "transport", *transport)
+	}
+}
+
+func serveHTTP(cfg *service.Config) {
+	router := service.GetRouter()
+	router.POST("/mcp", serveRequest)
+	router.GET("/circuit-breakers", getCircuitBreakers)
+	registerStreamableHTTP(router, "/mcp/jsonrpc", cfg)
+
 	address := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	logger.Info(fmt.Sprintf("Starting server on %s...", address))
+	var err error
 	if cfg.Server.Secure {
 		err = router.RunTLS(address, cfg.Server.CertPath, cfg.Server.KeyPath)
 	} else {
@@ -115,5 +154,13 @@ func main() {
 		logger.Error("Failed to start the service", "error", err)
 		return
 	}
+}
 
+func serveStdio() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logger.Info("Serving MCP over stdio")
+	if err := mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+		logger.Error("Failed to read from stdin", "error", err)
+	}
 }
@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"fmt"
+	"mcp-server/pkg/service"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// BackendUnavailableError is returned without calling an underlying API while the circuit breaker
+// of its host is open.
+type BackendUnavailableError struct {
+	Host string
+	// RetryAfter is the time left until the breaker lets a request through
+	RetryAfter time.Duration
+}
+
+func (e *BackendUnavailableError) Error() string {
+	return fmt.Sprintf("circuit breaker of %s is open", e.Host)
+}
+
+// CircuitBreakerState is the state of the circuit breaker of a backend host.
+type CircuitBreakerState struct {
+	Host  string `json:"host"`
+	State string `json:"state"`
+	// Requests and Failures are counted in the current interval of a closed breaker
+	Requests int        `json:"requests"`
+	Failures int        `json:"failures"`
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// breakerSettings are the thresholds of the circuit breakers.
+type breakerSettings struct {
+	failureRate      float64
+	minRequests      int
+	interval         time.Duration
+	cooldown         time.Duration
+	halfOpenRequests int
+}
+
+// circuitBreaker stops calling a backend host once the rate of failed requests in an interval
+// reaches the threshold. After the cooldown a limited number of concurrent probe requests are let
+// through, and the breaker closes once enough of them succeed or opens again when one fails.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	host        string
+	settings    breakerSettings
+	state       string
+	requests    int
+	failures    int
+	windowStart time.Time
+	openedAt    time.Time
+	// generation is incremented on each state change, so that requests allowed in an earlier
+	// state are not counted in the current one
+	generation uint64
+	// probes is the number of probe requests in flight while half-open
+	probes int
+	// successes is the number of probe requests that succeeded while half-open
+	successes int
+}
+
+// breakerTicket identifies the state of the breaker in which a request was allowed.
+type breakerTicket struct {
+	generation uint64
+}
+
+// circuitBreakers holds the circuit breaker of each backend host.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	settings breakerSettings
+	breakers map[string]*circuitBreaker
+}
+
+// breakerClock returns the current time, replaced in tests
+var breakerClock = time.Now
+
+// newCircuitBreakers creates the circuit breakers from the config.
+// Returns nil if circuit breakers are disabled.
+func newCircuitBreakers(config *service.Config) *circuitBreakers {
+	if config == nil || !config.Http.CircuitBreaker.Enabled {
+		return nil
+	}
+	cb := config.Http.CircuitBreaker
+	return newCircuitBreakersWith(breakerSettings{
+		failureRate:      cb.FailureRate,
+		minRequests:      cb.MinRequests,
+		interval:         time.Duration(cb.Interval) * time.Second,
+		cooldown:         time.Duration(cb.Cooldown) * time.Second,
+		halfOpenRequests: cb.HalfOpenRequests,
+	})
+}
+
+func newCircuitBreakersWith(settings breakerSettings) *circuitBreakers {
+	return &circuitBreakers{settings: settings, breakers: make(map[string]*circuitBreaker)}
+}
+
+// get returns the circuit breaker of a host, creating a closed one for new hosts.
+func (cbs *circuitBreakers) get(host string) *circuitBreaker {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+	breaker, ok := cbs.breakers[host]
+	if !ok {
+		breaker = &circuitBreaker{host: host, settings: cbs.settings, state: CircuitClosed, windowStart: breakerClock()}
+		cbs.breakers[host] = breaker
+	}
+	return breaker
+}
+
+// states returns the state of each circuit breaker sorted by host.
+func (cbs *circuitBreakers) states() []CircuitBreakerState {
+	cbs.mu.Lock()
+	breakers := make([]*circuitBreaker, 0, len(cbs.breakers))
+	for _, breaker := range cbs.breakers {
+		breakers = append(breakers, breaker)
+	}
+	cbs.mu.Unlock()
+	states := make([]CircuitBreakerState, 0, len(breakers))
+	for _, breaker := range breakers {
+		states = append(states, breaker.snapshot())
+	}
+	slices.SortFunc(states, func(a, b CircuitBreakerState) int {
+		return strings.Compare(a.Host, b.Host)
+	})
+	return states
+}
+
+// allow reports whether a request may be sent to the host and returns the ticket with which its
+// outcome is recorded. Open breakers turn half-open after the cooldown and let the probe requests
+// through.
+func (breaker *circuitBreaker) allow() (breakerTicket, error) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	now := breakerClock()
+	switch breaker.state {
+	case CircuitOpen:
+		if remaining := breaker.settings.cooldown - now.Sub(breaker.openedAt); remaining > 0 {
+			return breakerTicket{}, &BackendUnavailableError{Host: breaker.host, RetryAfter: remaining}
+		}
+		breaker.transition(CircuitHalfOpen, now)
+		fallthrough
+	case CircuitHalfOpen:
+		if breaker.probes >= breaker.settings.halfOpenRequests {
+			return breakerTicket{}, &BackendUnavailableError{Host: breaker.host}
+		}
+		breaker.probes++
+	case CircuitClosed:
+		if now.Sub(breaker.windowStart) >= breaker.settings.interval {
+			breaker.requests, breaker.failures = 0, 0
+			breaker.windowStart = now
+		}
+	}
+	return breakerTicket{generation: breaker.generation}, nil
+}
+
+// record counts the outcome of a request that was allowed, unless the breaker changed state since.
+// A failed probe opens the breaker again and it closes once halfOpenRequests probes succeeded.
+func (breaker *circuitBreaker) record(ticket breakerTicket, failed bool) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	if ticket.generation != breaker.generation {
+		return
+	}
+	now := breakerClock()
+	switch breaker.state {
+	case CircuitHalfOpen:
+		breaker.probes--
+		if failed {
+			breaker.transition(CircuitOpen, now)
+		} else if breaker.successes++; breaker.successes >= breaker.settings.halfOpenRequests {
+			breaker.transition(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		breaker.requests++
+		if failed {
+			breaker.failures++
+		}
+		if breaker.requests >= breaker.settings.minRequests &&
+			float64(breaker.failures) >= breaker.settings.failureRate*float64(breaker.requests) {
+			breaker.transition(CircuitOpen, now)
+		}
+	}
+}
+
+// release frees the slot of a probe request whose outcome says nothing about the host, such as a
+// request cancelled by the client.
+func (breaker *circuitBreaker) release(ticket breakerTicket) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	if ticket.generation == breaker.generation && breaker.state == CircuitHalfOpen {
+		breaker.probes--
+	}
+}
+
+func (breaker *circuitBreaker) transition(state string, now time.Time) {
+	logger.Warn("Circuit breaker changed state", "host", breaker.host, "from", breaker.state, "to", state,
+		"requests", breaker.requests, "failures", breaker.failures)
+	breaker.state = state
+	breaker.generation++
+	breaker.requests, breaker.failures = 0, 0
+	breaker.probes, breaker.successes = 0, 0
+	breaker.windowStart = now
+	if state == CircuitOpen {
+		breaker.openedAt = now
+	}
+}
+
+func (breaker *circuitBreaker) snapshot() CircuitBreakerState {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	state := CircuitBreakerState{
+		Host:     breaker.host,
+		State:    breaker.state,
+		Requests: breaker.requests,
+		Failures: breaker.failures,
+	}
+	if breaker.state != CircuitClosed {
+		openedAt := breaker.openedAt
+		state.OpenedAt = &openedAt
+	}
+	return state
+}
+
+// CircuitBreakerStates returns the state of the circuit breaker of each backend host that has been
+// called, or nil if circuit breakers are disabled.
+func CircuitBreakerStates() []CircuitBreakerState {
+	client := InitHttpClient()
+	if client.breakers == nil {
+		return nil
+	}
+	return client.breakers.states()
+}
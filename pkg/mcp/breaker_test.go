@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	settings := breakerSettings{failureRate: 0.5, minRequests: 4, interval: 10 * time.Second, cooldown: 5 * time.Second, halfOpenRequests: 1}
+	tripped := []string{"fail", "fail", "fail", "fail"}
+	tests := []struct {
+		name             string
+		halfOpenRequests int
+		events           []string
+		wantAllowed      bool
+		wantState        string
+	}{
+		{name: "opens at the failure rate", events: []string{"ok", "ok", "fail", "fail"}, wantAllowed: false, wantState: CircuitOpen},
+		{name: "stays closed below the minimum requests", events: []string{"fail", "fail", "fail"}, wantAllowed: true, wantState: CircuitClosed},
+		{name: "stays closed below the failure rate", events: []string{"ok", "ok", "ok", "fail"}, wantAllowed: true, wantState: CircuitClosed},
+		{name: "counts are reset each interval", events: []string{"fail", "fail", "ok", "wait 10s", "fail", "ok"}, wantAllowed: true, wantState: CircuitClosed},
+		{name: "cancelled requests are not counted", events: []string{"fail", "fail", "cancel", "cancel"}, wantAllowed: true, wantState: CircuitClosed},
+		{name: "open during the cooldown", events: append(tripped, "wait 4s"), wantAllowed: false, wantState: CircuitOpen},
+		{name: "half-open after the cooldown", events: append(tripped, "wait 5s"), wantAllowed: true, wantState: CircuitHalfOpen},
+		{name: "probes are limited", events: append(tripped, "wait 5s", "probe"), wantAllowed: false, wantState: CircuitHalfOpen},
+		{name: "successful probe closes", events: append(tripped, "wait 5s", "ok"), wantAllowed: true, wantState: CircuitClosed},
+		{name: "failed probe opens again", events: append(tripped, "wait 5s", "fail"), wantAllowed: false, wantState: CircuitOpen},
+		{name: "cancelled probe frees its slot", events: append(tripped, "wait 5s", "cancel"), wantAllowed: true, wantState: CircuitHalfOpen},
+		{name: "closes after the probes succeed", halfOpenRequests: 2, events: append(tripped, "wait 5s", "ok", "ok"), wantAllowed: true, wantState: CircuitClosed},
+		{name: "stays half-open until the probes succeed", halfOpenRequests: 2, events: append(tripped, "wait 5s", "ok"), wantAllowed: true, wantState: CircuitHalfOpen},
+		{name: "concurrent probes are limited", halfOpenRequests: 2, events: append(tripped, "wait 5s", "probe", "probe"), wantAllowed: false, wantState: CircuitHalfOpen},
+		{name: "request allowed while closed is not a probe", events: append([]string{"hold"}, append(tripped, "wait 5s", "probe", "held ok")...), wantAllowed: false, wantState: CircuitHalfOpen},
+		{name: "cancelled request allowed while closed frees no probe", events: append([]string{"hold"}, append(tripped, "wait 5s", "probe", "held cancel")...), wantAllowed: false, wantState: CircuitHalfOpen},
+		{name: "request allowed while closed does not open again", events: append([]string{"hold"}, append(tripped, "wait 5s", "held fail")...), wantAllowed: true, wantState: CircuitHalfOpen},
+	}
+	defer func() { breakerClock = time.Now }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			breakerClock = func() time.Time { return now }
+			settings := settings
+			if tt.halfOpenRequests > 0 {
+				settings.halfOpenRequests = tt.halfOpenRequests
+			}
+			breaker := newCircuitBreakersWith(settings).get("api.example.com")
+			var held breakerTicket
+			for _, event := range tt.events {
+				if duration, found := strings.CutPrefix(event, "wait "); found {
+					wait, _ := time.ParseDuration(duration)
+					now = now.Add(wait)
+					continue
+				}
+				ticket := held
+				if outcome, found := strings.CutPrefix(event, "held "); found {
+					event = outcome
+				} else {
+					var err error
+					if ticket, err = breaker.allow(); err != nil {
+						t.Fatalf("allow() before %s error = %v", event, err)
+					}
+				}
+				switch event {
+				case "ok", "fail":
+					breaker.record(ticket, event == "fail")
+				case "cancel":
+					breaker.release(ticket)
+				case "hold":
+					held = ticket
+				}
+			}
+			_, err := breaker.allow()
+			if allowed := err == nil; allowed != tt.wantAllowed {
+				t.Errorf("allow() error = %v, want allowed %v", err, tt.wantAllowed)
+			}
+			if state := breaker.snapshot().State; state != tt.wantState {
+				t.Errorf("state = %s, want %s", state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCallAPICircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer backend.Close()
+
+	client := InitHttpClient()
+	breakers := client.breakers
+	client.breakers = newCircuitBreakersWith(breakerSettings{failureRate: 0.5, minRequests: 2, interval: time.Minute, cooldown: time.Minute, halfOpenRequests: 1})
+	defer func() { client.breakers = breakers }()
+
+	request := newBackendRequest(backend.URL, ToolOptions{Retry: &RetryOptions{MaxAttempts: 1}})
+	for i := 0; i < 2; i++ {
+		resp, err := CallAPI(context.Background(), request)
+		if err != nil || resp.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("CallAPI() = %v, %v, want status 500", resp, err)
+		}
+	}
+	_, err := CallAPI(context.Background(), request)
+	toolErr := NewToolError(err)
+	if toolErr.Type != ErrorTypeBackendUnavailable || toolErr.RetryAfter != 60 {
+		t.Errorf("CallAPI() error = %s retry after %d, want %s retry after 60", toolErr.Type, toolErr.RetryAfter, ErrorTypeBackendUnavailable)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("backend called %d times, want 2", got)
+	}
+
+	host := strings.TrimPrefix(backend.URL, "http://")
+	states := CircuitBreakerStates()
+	if len(states) != 1 || states[0].Host != host || states[0].State != CircuitOpen || states[0].OpenedAt == nil {
+		t.Errorf("CircuitBreakerStates() = %+v, want %s open", states, host)
+	}
+}
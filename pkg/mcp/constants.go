@@ -1,7 +1,102 @@
 package mcp
 
+import "mcp-server/pkg/service"
+
+const (
+	ContentType          = "Content-Type"
+	HeaderCookie         = "Cookie"
+	ContentTypeJSON      = "application/json"
+	ContentTypeXML       = "application/xml"
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeMultipart = "multipart/form-data"
+	ContentTypeSSE       = "text/event-stream"
+)
+
+// Streamable HTTP transport headers
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "Mcp-Protocol-Version"
+	// HeaderResponseTruncated marks raw responses that were cut at the maximum response size
+	HeaderResponseTruncated = "X-Response-Truncated"
+	// HeaderNextPage carries the argument value that continues a paginated raw response
+	HeaderNextPage = "X-Next-Page"
+)
+
+const (
+	JSONRPCVersion        = "2.0"
+	LatestProtocolVersion = "2025-06-18"
+)
+
+var SupportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Schema extensions declaring the location and name of a parameter in the request
+const (
+	ExtensionIn   = "x-mcp-in"
+	ExtensionName = "x-mcp-name"
+)
+
+// Parameter locations
+const (
+	LocationPath   = "path"
+	LocationQuery  = "query"
+	LocationHeader = "header"
+	LocationCookie = "cookie"
+	LocationBody   = "body"
+)
+
+// JSON-RPC 2.0 error codes
 const (
-	ContentType     = "Content-Type"
-	ContentTypeJSON = "application/json"
-	ContentTypeXML  = "application/xml"
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
 )
+
+// MCP methods
+const (
+	MethodInitialize              = "initialize"
+	MethodPing                    = "ping"
+	MethodToolsList               = "tools/list"
+	MethodToolsCall               = "tools/call"
+	MethodNotificationInitialized = "notifications/initialized"
+)
+
+// defaultMaxBinaryResponseSize is the maximum size of binary responses when it is not configured
+const defaultMaxBinaryResponseSize = service.DefaultMaxBinaryResponseSize
+
+// defaultMaxResponseSize is the maximum size of text responses when it is not configured
+const defaultMaxResponseSize = service.DefaultMaxResponseSize
+
+// Pagination types
+const (
+	PaginationNextLink   = "next_link"
+	PaginationLinkHeader = "link_header"
+	PaginationCursor     = "cursor"
+	PaginationOffset     = "offset"
+	PaginationPage       = "page"
+)
+
+// defaultMaxPages is the maximum number of pages fetched by a call when it is not configured
+const defaultMaxPages = 5
+
+// Timeouts in seconds of calls to the underlying APIs when they are not configured
+const (
+	defaultConnectTimeout        = service.DefaultConnectTimeout
+	defaultTLSHandshakeTimeout   = service.DefaultTLSHandshakeTimeout
+	defaultResponseHeaderTimeout = service.DefaultResponseHeaderTimeout
+	defaultRequestTimeout        = service.DefaultTimeout
+)
+
+// maxCachedTransports is the number of transports kept for the timeouts and TLS profiles of tools
+const maxCachedTransports = 32
+
+// Retry policy of calls to the underlying APIs when it is not configured, backoffs are in
+// milliseconds
+const (
+	defaultMaxAttempts    = service.DefaultRetryMaxAttempts
+	defaultInitialBackoff = service.DefaultRetryInitialBackoff
+	defaultMaxBackoff     = service.DefaultRetryMaxBackoff
+)
+
+var defaultRetryStatusCodes = service.DefaultRetryStatusCodes
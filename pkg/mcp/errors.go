@@ -0,0 +1,210 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Types of failed tool calls
+const (
+	ErrorTypeValidation         = "validation_error"
+	ErrorTypeInvalidArguments   = "invalid_arguments"
+	ErrorTypeMissingParameter   = "missing_parameter"
+	ErrorTypeOutputValidation   = "output_validation_error"
+	ErrorTypeBackendTimeout     = "backend_timeout"
+	ErrorTypeBackendClient      = "backend_client_error"
+	ErrorTypeBackendServer      = "backend_server_error"
+	ErrorTypeTransport          = "transport_error"
+	ErrorTypeBackendUnavailable = "backend_unavailable"
+	ErrorTypeCancelled          = "cancelled"
+	ErrorTypeInternal           = "internal_error"
+)
+
+// statusClientClosedRequest is the status code of calls cancelled by the client
+const statusClientClosedRequest = 499
+
+// maxErrorMessageLength is the maximum length of a backend error message quoted in a tool error
+const maxErrorMessageLength = 200
+
+// Errors of calls whose arguments or API details cannot be turned into a request to the underlying
+// API. They are wrapped with the reason.
+var (
+	// ErrInvalidArguments is returned when the arguments are not a JSON object or do not fit the
+	// request, such as a request body that is not an object
+	ErrInvalidArguments = errors.New("invalid arguments")
+	// ErrInvalidAPIDetails is returned when the API details of the tool cannot be called, such as an
+	// unsupported method or content type or an invalid endpoint
+	ErrInvalidAPIDetails = errors.New("invalid API details")
+)
+
+// MissingParameterError is returned when a required parameter has no value in the arguments.
+type MissingParameterError struct {
+	Location string
+	Name     string
+}
+
+func (e *MissingParameterError) Error() string {
+	if e.Location == LocationPath {
+		return fmt.Sprintf("path parameter %s is missing", e.Name)
+	}
+	return fmt.Sprintf("required %s parameter %s is missing", e.Location, e.Name)
+}
+
+// ToolError is a failed tool call. It is returned as the JSON error payload of POST /mcp and as
+// the structured content of MCP tool error results.
+type ToolError struct {
+	// Message is a concise description of the error
+	Message string `json:"error"`
+	Type    string `json:"type"`
+	// BackendStatus is the status code returned by the underlying API
+	BackendStatus int `json:"backend_status,omitempty"`
+	// Details are the validation errors or the body of an error response of the underlying API
+	Details any `json:"details,omitempty"`
+	// RetryAfter is the number of seconds after which the call may succeed
+	RetryAfter int `json:"retry_after,omitempty"`
+	status     int
+	err        error
+}
+
+func (e *ToolError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.err
+}
+
+// StatusCode returns the HTTP status code of the error, which is the status code of the
+// underlying API for its error responses.
+func (e *ToolError) StatusCode() int {
+	if e.status != 0 {
+		return e.status
+	}
+	return http.StatusInternalServerError
+}
+
+// NewToolError classifies an error of calling the underlying API.
+func NewToolError(err error) *ToolError {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr
+	}
+	var validationErr *ArgumentsValidationError
+	if errors.As(err, &validationErr) {
+		return &ToolError{Message: "Invalid arguments", Type: ErrorTypeValidation, Details: validationErr.Errors, status: http.StatusBadRequest, err: err}
+	}
+	var missingErr *MissingParameterError
+	if errors.As(err, &missingErr) {
+		message := fmt.Sprintf("Missing required %s parameter %s", missingErr.Location, missingErr.Name)
+		return &ToolError{Message: message, Type: ErrorTypeMissingParameter, status: http.StatusBadRequest, err: err}
+	}
+	if errors.Is(err, ErrInvalidArguments) || errors.Is(err, ErrInvalidAPIDetails) {
+		message := err.Error()
+		return &ToolError{Message: strings.ToUpper(message[:1]) + message[1:], Type: ErrorTypeInvalidArguments, status: http.StatusBadRequest, err: err}
+	}
+	var outputErr *OutputValidationError
+	if errors.As(err, &outputErr) {
+		return &ToolError{Message: "Response does not match the output schema", Type: ErrorTypeOutputValidation, Details: outputErr.Errors, status: http.StatusBadGateway, err: err}
+	}
+	var unavailableErr *BackendUnavailableError
+	if errors.As(err, &unavailableErr) {
+		message := fmt.Sprintf("The underlying API at %s is unavailable after repeated failures", unavailableErr.Host)
+		retryAfter := int(math.Ceil(unavailableErr.RetryAfter.Seconds()))
+		if retryAfter > 0 {
+			message += fmt.Sprintf(", try again in %d seconds", retryAfter)
+		}
+		return &ToolError{Message: message, Type: ErrorTypeBackendUnavailable, RetryAfter: retryAfter, status: http.StatusServiceUnavailable, err: err}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &ToolError{Message: "The underlying API did not respond in time", Type: ErrorTypeBackendTimeout, status: http.StatusGatewayTimeout, err: err}
+	}
+	if errors.Is(err, context.Canceled) {
+		// The client went away, nginx uses the same status code
+		return &ToolError{Message: "The call was cancelled", Type: ErrorTypeCancelled, status: statusClientClosedRequest, err: err}
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		message := fmt.Sprintf("Failed to reach the underlying API: %v", urlErr.Err)
+		return &ToolError{Message: message, Type: ErrorTypeTransport, status: http.StatusBadGateway, err: err}
+	}
+	return &ToolError{Message: fmt.Sprintf("Failed to call underlying API: %s", err.Error()), Type: ErrorTypeInternal, err: err}
+}
+
+// NewBackendError creates the error of an error response of the underlying API, keeping its
+// status code and body.
+func NewBackendError(resp *APIResponse) *ToolError {
+	errorType := ErrorTypeBackendClient
+	if resp.StatusCode >= http.StatusInternalServerError {
+		errorType = ErrorTypeBackendServer
+	}
+	message := fmt.Sprintf("The underlying API returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	if reason := backendErrorMessage(resp.Text); reason != "" {
+		message += ": " + reason
+	}
+	toolErr := &ToolError{Message: message, Type: errorType, BackendStatus: resp.StatusCode, status: resp.StatusCode}
+	if resp.Text != "" && !isBinaryResponse(responseMediaType(resp.ContentType)) {
+		if json.Valid([]byte(resp.Text)) {
+			toolErr.Details = json.RawMessage(resp.Text)
+		} else {
+			toolErr.Details = resp.Text
+		}
+	}
+	return toolErr
+}
+
+// backendErrorMessage extracts a short message from the body of an error response, looking for
+// the message fields commonly used by APIs in JSON bodies.
+func backendErrorMessage(text string) string {
+	var body map[string]any
+	if err := json.Unmarshal([]byte(text), &body); err != nil {
+		if json.Valid([]byte(text)) {
+			return ""
+		}
+		return shortenMessage(text)
+	}
+	for _, key := range []string{"message", "error_description", "detail", "title", "error", "description"} {
+		switch value := body[key].(type) {
+		case string:
+			return shortenMessage(value)
+		case map[string]any:
+			if message, ok := value["message"].(string); ok {
+				return shortenMessage(message)
+			}
+		}
+	}
+	return ""
+}
+
+// shortenMessage collapses the whitespace of a message and cuts it at the maximum length.
+func shortenMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxErrorMessageLength {
+		return string(runes[:maxErrorMessageLength]) + "..."
+	}
+	return message
+}
+
+// Result returns the error as an MCP tool error result with an LLM friendly message and the
+// error payload as structured content.
+func (e *ToolError) Result() *CallToolResult {
+	text := e.Message
+	if errors, ok := e.Details.([]ValidationError); ok {
+		text = formatValidationErrors(e.Message+":", errors)
+	}
+	result := &CallToolResult{Content: []Content{{Type: "text", Text: text}}, IsError: true}
+	if payload, err := json.Marshal(e); err == nil {
+		result.StructuredContent = payload
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewToolError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantType    string
+		wantStatus  int
+		wantMessage string
+	}{
+		{
+			name:        "invalid arguments",
+			err:         &ArgumentsValidationError{Errors: []ValidationError{{Path: "arguments.id", Message: "is required"}}},
+			wantType:    ErrorTypeValidation,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Invalid arguments",
+		},
+		{
+			name:        "missing parameter",
+			err:         fmt.Errorf("failed to process endpoint: %w", &MissingParameterError{Location: LocationQuery, Name: "q"}),
+			wantType:    ErrorTypeMissingParameter,
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "Missing required query parameter q",
+		},
+		{
+			name:        "output mismatch",
+			err:         &OutputValidationError{Errors: []ValidationError{{Path: "response.id", Message: "expected type string but got integer"}}},
+			wantType:    ErrorTypeOutputValidation,
+			wantStatus:  http.StatusBadGateway,
+			wantMessage: "Response does not match the output schema",
+		},
+		{
+			name:        "timeout",
+			err:         &url.Error{Op: "Get", URL: "http://localhost", Err: context.DeadlineExceeded},
+			wantType:    ErrorTypeBackendTimeout,
+			wantStatus:  http.StatusGatewayTimeout,
+			wantMessage: "The underlying API did not respond in time",
+		},
+		{
+			name:        "transport failure",
+			err:         &url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")},
+			wantType:    ErrorTypeTransport,
+			wantStatus:  http.StatusBadGateway,
+			wantMessage: "Failed to reach the underlying API: connection refused",
+		},
+		{
+			name:        "circuit breaker open",
+			err:         &BackendUnavailableError{Host: "localhost:9090", RetryAfter: 1500 * time.Millisecond},
+			wantType:    ErrorTypeBackendUnavailable,
+			wantStatus:  http.StatusServiceUnavailable,
+			wantMessage: "The underlying API at localhost:9090 is unavailable after repeated failures, try again in 2 seconds",
+		},
+		{
+			name:        "other error",
+			err:         errors.New("failed to marshal request body"),
+			wantType:    ErrorTypeInternal,
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "Failed to call underlying API: failed to marshal request body",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewToolError(tt.err)
+			if got.Type != tt.wantType || got.StatusCode() != tt.wantStatus || got.Message != tt.wantMessage {
+				t.Errorf("NewToolError() = %s %d %q, want %s %d %q", got.Type, got.StatusCode(), got.Message, tt.wantType, tt.wantStatus, tt.wantMessage)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("NewToolError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestCallAPIInvalidArguments(t *testing.T) {
+	xmlSchema := `{"type":"object","contentType":"application/xml","properties":{"requestBody":{"type":"string"}}}`
+	tests := []struct {
+		name        string
+		arguments   string
+		schema      string
+		verb        string
+		endpoint    string
+		wantErr     error
+		wantMessage string
+	}{
+		{
+			name:        "malformed arguments",
+			arguments:   `{"id":`,
+			wantErr:     ErrInvalidArguments,
+			wantMessage: "Invalid arguments: arguments must be a JSON object: unexpected end of JSON input",
+		},
+		{
+			name:        "arguments that are not an object",
+			arguments:   `[1]`,
+			wantErr:     ErrInvalidArguments,
+			wantMessage: "Invalid arguments: arguments must be a JSON object: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		},
+		{
+			name:        "request body that is not an object",
+			arguments:   `{"requestBody":"text"}`,
+			schema:      xmlSchema,
+			verb:        "POST",
+			wantErr:     ErrInvalidArguments,
+			wantMessage: "Invalid arguments: request body must be an object to be sent as XML",
+		},
+		{
+			name:        "unsupported content type",
+			arguments:   `{"requestBody":"text"}`,
+			schema:      `{"type":"object","contentType":"text/csv","properties":{"requestBody":{"type":"string"}}}`,
+			verb:        "POST",
+			wantErr:     ErrInvalidAPIDetails,
+			wantMessage: "Invalid API details: unsupported content type: text/csv",
+		},
+		{
+			name:        "unsupported method",
+			arguments:   `{}`,
+			verb:        "TRACE",
+			wantErr:     ErrInvalidAPIDetails,
+			wantMessage: "Invalid API details: unsupported HTTP method: TRACE",
+		},
+		{
+			name:        "invalid endpoint",
+			arguments:   `{}`,
+			endpoint:    "orders.example.com",
+			wantErr:     ErrInvalidAPIDetails,
+			wantMessage: "Invalid API details: invalid endpoint: orders.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest("http://localhost:1", ToolOptions{})
+			request.Arguments, request.Schema = tt.arguments, tt.schema
+			if tt.verb != "" {
+				request.Backend.Verb = tt.verb
+			}
+			if tt.endpoint != "" {
+				request.Backend.Endpoint = tt.endpoint
+			}
+			_, err := CallAPI(context.Background(), request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CallAPI() error = %v, want %v", err, tt.wantErr)
+			}
+			toolErr := NewToolError(err)
+			if toolErr.Type != ErrorTypeInvalidArguments || toolErr.StatusCode() != http.StatusBadRequest || toolErr.Message != tt.wantMessage {
+				t.Errorf("NewToolError() = %s %d %q, want %s %d %q", toolErr.Type, toolErr.StatusCode(), toolErr.Message, ErrorTypeInvalidArguments, http.StatusBadRequest, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestBackendErrorMessage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: `{"message":"Order 7 was not found"}`, want: "Order 7 was not found"},
+		{text: `{"type":"about:blank","title":"Forbidden","status":403}`, want: "Forbidden"},
+		{text: `{"error":"invalid_grant","error_description":"Token expired"}`, want: "Token expired"},
+		{text: `{"code":42}`, want: ""},
+		{text: `[1,2]`, want: ""},
+		{text: "Service\n  unavailable ", want: "Service unavailable"},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := backendErrorMessage(tt.text); got != tt.want {
+				t.Errorf("backendErrorMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+const defaultFileContentType = "application/octet-stream"
+
+// filePart is a file to upload in a multipart body, sent in the arguments as an object with the
+// base64 encoded content, the file name and optionally the media type.
+type filePart struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// encodeFormBody encodes an object as an application/x-www-form-urlencoded body.
+// Array values are sent as repeated fields and object values are exploded into their properties,
+// following the OpenAPI defaults for form bodies.
+func encodeFormBody(body map[string]any) []byte {
+	values := url.Values{}
+	for _, k := range sortedKeys(body) {
+		switch val := body[k].(type) {
+		case nil:
+			continue
+		case []any:
+			for _, item := range val {
+				values.Add(k, formatParamValue(item))
+			}
+		case map[string]any:
+			for _, property := range sortedKeys(val) {
+				values.Add(property, formatParamValue(val[property]))
+			}
+		default:
+			values.Add(k, formatParamValue(val))
+		}
+	}
+	return []byte(values.Encode())
+}
+
+// encodeMultipartBody encodes an object as a multipart/form-data body.
+// File objects are sent as file parts, other objects as JSON parts and arrays as repeated parts.
+// Returns the body and the content type carrying the boundary.
+func encodeMultipartBody(body map[string]any) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, k := range sortedKeys(body) {
+		values, ok := body[k].([]any)
+		if !ok {
+			values = []any{body[k]}
+		}
+		for _, value := range values {
+			if err := writeMultipartField(writer, k, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+func writeMultipartField(writer *multipart.Writer, name string, value any) error {
+	// Part headers are written verbatim, so line breaks would inject headers or boundaries
+	if strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("%w: invalid form field name %q", ErrInvalidArguments, name)
+	}
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		file, ok, err := parseFilePart(val)
+		if err != nil {
+			return fmt.Errorf("%w: invalid file for form field %s: %v", ErrInvalidArguments, name, err)
+		}
+		if ok {
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+				escapeQuotes(name), escapeQuotes(file.Filename)))
+			header.Set(ContentType, file.ContentType)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return err
+			}
+			_, err = part.Write(file.Content)
+			return err
+		}
+		content, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(name)))
+		header.Set(ContentType, ContentTypeJSON)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(content)
+		return err
+	default:
+		return writer.WriteField(name, formatParamValue(val))
+	}
+}
+
+// parseFilePart returns the file described by an object with content and filename properties.
+// Reports false if the object does not describe a file.
+func parseFilePart(value map[string]any) (*filePart, bool, error) {
+	content, hasContent := value["content"].(string)
+	filename, hasFilename := value["filename"].(string)
+	if !hasContent || !hasFilename {
+		return nil, false, nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("content is not valid base64")
+	}
+	if strings.ContainsAny(filename, "\r\n") {
+		return nil, false, fmt.Errorf("filename contains a line break")
+	}
+	contentType, _ := value["contentType"].(string)
+	if contentType == "" {
+		contentType = defaultFileContentType
+	} else {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil || strings.ContainsAny(contentType, "\r\n") {
+			return nil, false, fmt.Errorf("invalid content type %q", contentType)
+		}
+		contentType = mime.FormatMediaType(mediaType, params)
+	}
+	return &filePart{Filename: filename, ContentType: contentType, Content: decoded}, true, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
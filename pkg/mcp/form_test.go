@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestEncodeFormBody(t *testing.T) {
+	body := map[string]any{
+		"grant_type": "client_credentials",
+		"scope":      []any{"read", "write"},
+		"extra":      map[string]any{"a": "1 + 1"},
+		"empty":      nil,
+	}
+	want := "a=1+%2B+1&grant_type=client_credentials&scope=read&scope=write"
+	if got := string(encodeFormBody(body)); got != want {
+		t.Errorf("encodeFormBody() = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeMultipartBody(t *testing.T) {
+	body := map[string]any{
+		"title": "Report",
+		"file": map[string]any{
+			"content":     "aGVsbG8=",
+			"filename":    "hello.txt",
+			"contentType": "text/plain",
+		},
+		"meta": map[string]any{"tags": []any{"a"}},
+	}
+	data, contentType, err := encodeMultipartBody(body)
+	if err != nil {
+		t.Fatalf("encodeMultipartBody() error = %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != ContentTypeMultipart {
+		t.Fatalf("encodeMultipartBody() content type = %v", contentType)
+	}
+
+	type part struct {
+		name, filename, contentType, content string
+	}
+	want := []part{
+		{"file", "hello.txt", "text/plain", "hello"},
+		{"meta", "", ContentTypeJSON, `{"tags":["a"]}`},
+		{"title", "", "", "Report"},
+	}
+	reader := multipart.NewReader(strings.NewReader(string(data)), params["boundary"])
+	for i := 0; ; i++ {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			if i != len(want) {
+				t.Errorf("encodeMultipartBody() wrote %d parts, want %d", i, len(want))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		if i >= len(want) {
+			t.Fatalf("encodeMultipartBody() wrote unexpected part %s", p.FormName())
+		}
+		content, _ := io.ReadAll(p)
+		got := part{p.FormName(), p.FileName(), p.Header.Get(ContentType), string(content)}
+		if got != want[i] {
+			t.Errorf("part %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestEncodeMultipartBodyInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"invalid base64 content", map[string]any{"file": map[string]any{"content": "not base64!", "filename": "a.bin"}}},
+		{"line break in filename", map[string]any{"file": map[string]any{"content": "aGVsbG8=", "filename": "a.txt\"\r\nContent-Type: text/html"}}},
+		{"line break in content type", map[string]any{"file": map[string]any{"content": "aGVsbG8=", "filename": "a.txt", "contentType": "text/plain\r\n\r\n--boundary"}}},
+		{"invalid content type", map[string]any{"file": map[string]any{"content": "aGVsbG8=", "filename": "a.txt", "contentType": "text plain"}}},
+		{"line break in field name", map[string]any{"title\r\nX-Injected: 1": "Report"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := encodeMultipartBody(tt.body); err == nil {
+				t.Error("encodeMultipartBody() expected an error")
+			}
+		})
+	}
+}
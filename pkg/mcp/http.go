@@ -1,66 +1,222 @@
 package mcp
 
 import (
+	"container/list"
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"mcp-server/pkg/service"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 )
 
 var (
-	syncOnce        sync.Once
-	httpClient      *MCPHTTPClient
-	skipVerifying   bool
-	maxIdleConns    int
-	idleConnTimeout int
+	syncOnce   sync.Once
+	httpClient *MCPHTTPClient
 )
 
 type MCPHTTPClient struct {
 	httpClient *http.Client
 	UserAgent  string
+	timeouts   transportTimeouts
+	// settings are the [http] settings of the transports, read once with the config
+	settings transportSettings
+	// transports holds the transports of tools that override the connection timeouts or use a TLS
+	// profile
+	transports  *transportCache
+	tlsProfiles *tlsProfiles
+	proxy       *proxySelector
+	// breakers are the circuit breakers of the backend hosts, nil if they are disabled
+	breakers *circuitBreakers
+}
+
+// transportKey identifies the transport of a combination of timeouts and TLS profile.
+type transportKey struct {
+	timeouts   transportTimeouts
+	tlsProfile string
+}
+
+// transportCache holds the most recently used transports. Timeouts can be set on each call, so the
+// least recently used transports are evicted and their idle connections closed past the maximum.
+type transportCache struct {
+	mu         sync.Mutex
+	max        int
+	transports map[transportKey]*list.Element
+	// recent orders the transports from the most recently used
+	recent *list.List
+}
+
+type cachedTransport struct {
+	key       transportKey
+	transport *http.Transport
+}
+
+func newTransportCache(max int) *transportCache {
+	return &transportCache{max: max, transports: make(map[transportKey]*list.Element), recent: list.New()}
+}
+
+// get returns the transport of the key, creating it if it is not cached.
+func (cache *transportCache) get(key transportKey, create func() *http.Transport) *http.Transport {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.transports[key]; ok {
+		cache.recent.MoveToFront(element)
+		return element.Value.(*cachedTransport).transport
+	}
+	transport := create()
+	cache.transports[key] = cache.recent.PushFront(&cachedTransport{key: key, transport: transport})
+	for cache.recent.Len() > cache.max {
+		evicted := cache.recent.Remove(cache.recent.Back()).(*cachedTransport)
+		delete(cache.transports, evicted.key)
+		evicted.transport.CloseIdleConnections()
+	}
+	return transport
 }
 
+// transportSettings are the connection pool and TLS settings shared by all transports.
+type transportSettings struct {
+	insecure        bool
+	maxIdleConns    int
+	idleConnTimeout time.Duration
+}
+
+// transportTimeouts are the timeouts of the connections to the underlying APIs.
+type transportTimeouts struct {
+	connect        time.Duration
+	tlsHandshake   time.Duration
+	responseHeader time.Duration
+}
+
+// InitHttpClient returns the HTTP client of the underlying APIs, created with the config on the
+// first call.
 func InitHttpClient() *MCPHTTPClient {
-	skipVerifying = service.GetConfig().Http.Insecure
-	maxIdleConns = service.GetConfig().Http.MaxIdleConns
-	idleConnTimeout = service.GetConfig().Http.IdleConnTimeout
 	syncOnce.Do(func() {
 		if httpClient == nil {
-			client := http.Client{
-				Transport: &http.Transport{
-					MaxIdleConns:    maxIdleConns,
-					IdleConnTimeout: time.Duration(idleConnTimeout) * time.Second,
-					TLSClientConfig: &tls.Config{
-						InsecureSkipVerify: skipVerifying,
-					},
-				},
+			timeouts := transportTimeouts{
+				connect:        defaultConnectTimeout * time.Second,
+				tlsHandshake:   defaultTLSHandshakeTimeout * time.Second,
+				responseHeader: defaultResponseHeaderTimeout * time.Second,
+			}
+			var settings transportSettings
+			var profiles map[string]service.TLSProfile
+			var proxy service.Proxy
+			if config := service.GetConfig(); config != nil {
+				timeouts = transportTimeouts{
+					connect:        time.Duration(config.Http.ConnectTimeout) * time.Second,
+					tlsHandshake:   time.Duration(config.Http.TLSHandshakeTimeout) * time.Second,
+					responseHeader: time.Duration(config.Http.ResponseHeaderTimeout) * time.Second,
+				}
+				settings = transportSettings{
+					insecure:        config.Http.Insecure,
+					maxIdleConns:    config.Http.MaxIdleConns,
+					idleConnTimeout: time.Duration(config.Http.IdleConnTimeout) * time.Second,
+				}
+				profiles = config.Http.TLSProfiles
+				proxy = config.Http.Proxy
 			}
 			httpClient = &MCPHTTPClient{
-				httpClient: &client,
-				UserAgent:  "Bijira-MCP-Client-Go/0.1",
+				UserAgent:   "Bijira-MCP-Client-Go/0.1",
+				timeouts:    timeouts,
+				settings:    settings,
+				tlsProfiles: newTLSProfiles(profiles),
+				proxy:       newProxySelector(proxy),
+				breakers:    newCircuitBreakers(service.GetConfig()),
+				transports:  newTransportCache(maxCachedTransports),
 			}
+			httpClient.httpClient = &http.Client{Transport: httpClient.newTransport(timeouts, nil)}
 		}
 	})
 	return httpClient
 }
 
-func (client *MCPHTTPClient) DoRequest(request *http.Request) (*http.Response, error) {
-	resp, err := client.httpClient.Do(request)
+// newTransport creates a transport with the timeouts and TLS configuration, which defaults to the
+// [http] insecure setting when nil. Requests are sent through the proxy selected for their host.
+func (client *MCPHTTPClient) newTransport(timeouts transportTimeouts, tlsConfig *tls.Config) *http.Transport {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: client.settings.insecure}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	dialer := &net.Dialer{
+		Timeout:   timeouts.connect,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy: func(request *http.Request) (*url.URL, error) {
+			return client.proxy.proxy(request)
+		},
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeouts.tlsHandshake,
+		ResponseHeaderTimeout: timeouts.responseHeader,
+		MaxIdleConns:          client.settings.maxIdleConns,
+		IdleConnTimeout:       client.settings.idleConnTimeout,
+		TLSClientConfig:       tlsConfig,
+	}
+}
+
+// DoRequest sends the request with the connection timeouts and TLS profile of the tool.
+// Tools that override the timeouts or use a TLS profile share a cached transport per combination
+// of timeouts and profile.
+// Returns a BackendUnavailableError without sending the request while the circuit breaker of the
+// host is open.
+func (client *MCPHTTPClient) DoRequest(request *http.Request, options ToolOptions) (*http.Response, error) {
+	timeouts := client.timeouts
+	if options.ConnectTimeout > 0 {
+		timeouts.connect = time.Duration(options.ConnectTimeout) * time.Second
+	}
+	if options.TLSHandshakeTimeout > 0 {
+		timeouts.tlsHandshake = time.Duration(options.TLSHandshakeTimeout) * time.Second
+	}
+	if options.ResponseHeaderTimeout > 0 {
+		timeouts.responseHeader = time.Duration(options.ResponseHeaderTimeout) * time.Second
+	}
+	profile, tlsConfig, err := client.tlsProfiles.profile(request.URL.Host, options)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := client.httpClient
+	if key := (transportKey{timeouts: timeouts, tlsProfile: profile}); key != (transportKey{timeouts: client.timeouts}) {
+		transport := client.transports.get(key, func() *http.Transport {
+			return client.newTransport(timeouts, tlsConfig)
+		})
+		httpClient = &http.Client{Transport: transport}
+	}
+	var breaker *circuitBreaker
+	var ticket breakerTicket
+	if client.breakers != nil {
+		breaker = client.breakers.get(request.URL.Host)
+		if ticket, err = breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := httpClient.Do(request)
+	if breaker != nil {
+		if err != nil && errors.Is(request.Context().Err(), context.Canceled) {
+			// Calls cancelled by the client say nothing about the host
+			breaker.release(ticket)
+		} else {
+			breaker.record(ticket, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (client *MCPHTTPClient) GenerateRequest(httpRequest *TransformedRequest) (*http.Request, error) {
+// GenerateRequest creates the request to the underlying API, which is cancelled with the context.
+func (client *MCPHTTPClient) GenerateRequest(ctx context.Context, httpRequest *TransformedRequest) (*http.Request, error) {
 	var req *http.Request
 	var err error
 	if httpRequest.Body == nil {
-		req, err = http.NewRequest(httpRequest.Method, httpRequest.URL, nil)
+		req, err = http.NewRequestWithContext(ctx, httpRequest.Method, httpRequest.URL, nil)
 	} else {
-		req, err = http.NewRequest(httpRequest.Method, httpRequest.URL, httpRequest.Body)
+		req, err = http.NewRequestWithContext(ctx, httpRequest.Method, httpRequest.URL, httpRequest.Body)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
@@ -72,3 +228,52 @@ func (client *MCPHTTPClient) GenerateRequest(httpRequest *TransformedRequest) (*
 
 	return req, nil
 }
+
+// hostPatterns maps backend hosts to names, such as the TLS profile or proxy of the host.
+// Patterns are a host, a host:port or a *.domain matching the subdomains of the domain.
+type hostPatterns struct {
+	// hosts maps the lowercase host or host:port of a pattern to its name
+	hosts map[string]string
+	// domains maps the lowercase domain of a *.domain pattern to its name
+	domains map[string]string
+}
+
+func newHostPatterns() *hostPatterns {
+	return &hostPatterns{hosts: make(map[string]string), domains: make(map[string]string)}
+}
+
+func (patterns *hostPatterns) add(pattern string, name string) {
+	pattern = strings.ToLower(pattern)
+	if domain, found := strings.CutPrefix(pattern, "*."); found {
+		patterns.domains[domain] = name
+	} else {
+		patterns.hosts[pattern] = name
+	}
+}
+
+// match returns the name of a host, preferring the pattern of its host:port over the pattern of
+// its host name and the pattern of its closest *.domain. Returns an empty name if no pattern
+// matches.
+func (patterns *hostPatterns) match(host string) string {
+	host = strings.ToLower(host)
+	if name, ok := patterns.hosts[host]; ok {
+		return name
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if name, ok := patterns.hosts[hostname]; ok {
+		return name
+	}
+	for domain := hostname; ; {
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			return ""
+		}
+		if name, ok := patterns.domains[parent]; ok {
+			return name
+		}
+		domain = parent
+	}
+}
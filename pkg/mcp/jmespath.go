@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// maxExactDigits is the number of significant digits of decimal numbers that float64 represents
+// exactly
+const maxExactDigits = 15
+
+// jmesPath is a compiled JMESPath expression.
+type jmesPath struct {
+	expression *jmespath.JMESPath
+}
+
+// compileJMESPath parses a JMESPath expression.
+func compileJMESPath(expression string) (*jmesPath, error) {
+	compiled, err := jmespath.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &jmesPath{expression: compiled}, nil
+}
+
+// search evaluates the expression against a decoded JSON value. Numbers are evaluated as float64,
+// except those with more digits than float64 represents exactly, which are returned as received
+// but are not compared or passed to functions as numbers.
+func (path *jmesPath) search(value any) (any, error) {
+	return path.expression.Search(toJMESValue(value))
+}
+
+// toJMESValue converts the json.Number values of a decoded JSON value to float64 when they are
+// represented exactly.
+func toJMESValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, child := range v {
+			converted[key] = toJMESValue(child)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = toJMESValue(item)
+		}
+		return converted
+	case json.Number:
+		mantissa, _, _ := strings.Cut(strings.ToLower(string(v)), "e")
+		digits := strings.Trim(strings.NewReplacer("-", "", "+", "", ".", "").Replace(mantissa), "0")
+		if len(digits) <= maxExactDigits {
+			if number, err := v.Float64(); err == nil {
+				return number
+			}
+		}
+	}
+	return value
+}
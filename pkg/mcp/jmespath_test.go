@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"bytes"
+	"testing"
+)
+
+const searchDocument = `{
+	"items": [
+		{"id": 1, "name": "alpha", "status": "active", "price": 10, "tags": ["a", "b"]},
+		{"id": 2, "name": "beta", "status": "inactive", "price": 25, "tags": ["c"]},
+		{"id": 3, "name": "gamma", "status": "active", "price": 5, "tags": []}
+	],
+	"meta": {"total": 3, "page": {"size": 10}},
+	"store": {"book": {"price": 8}, "pen": {"price": 2}},
+	"locations": [
+		{"name": "Seattle", "state": "WA"},
+		{"name": "New York", "state": "NY"},
+		{"name": "Bellevue", "state": "WA"},
+		{"name": "Olympia", "state": "WA"}
+	]
+}`
+
+func TestJMESPathSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		document   string
+		expression string
+		want       string
+	}{
+		{name: "sub-expression", expression: "meta.page.size", want: `10`},
+		{name: "missing field", expression: "missing.field", want: `null`},
+		{name: "index", expression: "items[0].name", want: `"alpha"`},
+		{name: "negative index", expression: "items[-1].id", want: `3`},
+		{name: "list projection", expression: "items[*].id", want: `[1,2,3]`},
+		// The order of the values of an object projection is not defined, so they are sorted
+		{name: "object projection", expression: "sort(store.*.price)", want: `[2,8]`},
+		{name: "multiselect hash", expression: "items[*].{key: id, label: name}", want: `[{"key":1,"label":"alpha"},{"key":2,"label":"beta"},{"key":3,"label":"gamma"}]`},
+		{name: "multiselect list", expression: "items[*].[id, status]", want: `[[1,"active"],[2,"inactive"],[3,"active"]]`},
+		{name: "filter", expression: "items[?status == 'active'].name", want: `["alpha","gamma"]`},
+		{name: "filter with number literal", expression: "items[?price > `8` && status == 'active'].id", want: `[1]`},
+		{name: "negated filter", expression: "items[?!(price < `20`)].id", want: `[2]`},
+		{name: "flatten", expression: "items[].tags[]", want: `["a","b","c"]`},
+		{name: "slice", expression: "items[0:2].id", want: `[1,2]`},
+		{name: "reversed slice", expression: "items[::-1].id", want: `[3,2,1]`},
+		{name: "pipe stops the projection", expression: "items[*].id | [0]", want: `1`},
+		{name: "or expression", expression: "missing || meta.total", want: `3`},
+		{name: "quoted identifier", expression: `"meta".total`, want: `3`},
+		{name: "current node", expression: "@.meta.total", want: `3`},
+		// Functions and expression references of the JMESPath specification
+		{name: "length function", expression: "length(items)", want: `3`},
+		{name: "sort_by expression reference", expression: "sort_by(items, &price)[*].name", want: `["gamma","alpha","beta"]`},
+		{name: "max_by expression reference", expression: "max_by(items, &price).name", want: `"beta"`},
+		{name: "sum function", expression: "sum(items[*].price)", want: `40`},
+		{name: "contains function in a filter", expression: "items[?contains(tags, 'a')].id", want: `[1]`},
+		{name: "pipe into functions", expression: "locations[?state == 'WA'].name | sort(@) | {WashingtonCities: join(', ', @)}", want: `{"WashingtonCities":"Bellevue, Olympia, Seattle"}`},
+		{name: "numbers are kept as received", document: `{"amount":12345678901234567890.10,"id":1}`, expression: "amount", want: `12345678901234567890.10`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := tt.document
+			if document == "" {
+				document = searchDocument
+			}
+			value, keyOrder, err := decodeOrdered(document)
+			if err != nil {
+				t.Fatalf("failed to decode document: %v", err)
+			}
+			path, err := compileJMESPath(tt.expression)
+			if err != nil {
+				t.Fatalf("compileJMESPath() error = %v", err)
+			}
+			result, err := path.search(value)
+			if err != nil {
+				t.Fatalf("search() error = %v", err)
+			}
+			var got bytes.Buffer
+			if err := writeOrdered(&got, result, keyOrder); err != nil {
+				t.Fatalf("failed to encode result: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("search() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileJMESPathInvalid(t *testing.T) {
+	for _, expression := range []string{"", "items[", "items[*].{id", "a..b", "items[?]", "'unterminated", "sort_by(items, &)"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := compileJMESPath(expression); err == nil {
+				t.Errorf("compileJMESPath(%q) expected an error", expression)
+			}
+		})
+	}
+}
+
+func TestJMESPathSearchInvalidArguments(t *testing.T) {
+	value, _, err := decodeOrdered(searchDocument)
+	if err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	for _, expression := range []string{"length(meta.total)", "unknown(items)", "sort_by(items, &tags)"} {
+		t.Run(expression, func(t *testing.T) {
+			path, err := compileJMESPath(expression)
+			if err != nil {
+				t.Fatalf("compileJMESPath() error = %v", err)
+			}
+			if _, err := path.search(value); err == nil {
+				t.Errorf("search(%q) expected an error", expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/speakeasy-api/jsonpath/pkg/jsonpath"
+	"github.com/speakeasy-api/jsonpath/pkg/jsonpath/token"
+	"gopkg.in/yaml.v3"
+)
+
+// jsonPath is a compiled RFC 9535 JSONPath expression. The library evaluates expressions against
+// YAML nodes, so decoded JSON values are converted to nodes that keep numbers as received.
+type jsonPath struct {
+	path *jsonpath.JSONPath
+	// singular is set for paths that select at most one value, which is returned instead of the
+	// list of matches
+	singular bool
+}
+
+// singularTokens are the tokens of the singular queries of RFC 9535, made of name and index
+// selectors only
+var singularTokens = map[token.Token]bool{
+	token.ROOT:           true,
+	token.CHILD:          true,
+	token.STRING:         true,
+	token.STRING_LITERAL: true,
+	token.INTEGER:        true,
+	token.BRACKET_LEFT:   true,
+	token.BRACKET_RIGHT:  true,
+}
+
+// compileJSONPath parses a JSONPath expression.
+func compileJSONPath(expression string) (*jsonPath, error) {
+	path, err := jsonpath.NewPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	singular := true
+	for _, info := range token.NewTokenizer(expression).Tokenize() {
+		if !singularTokens[info.Token] {
+			singular = false
+			break
+		}
+	}
+	return &jsonPath{path: path, singular: singular}, nil
+}
+
+// search evaluates the path against a decoded JSON value whose keys are ordered by keyOrder.
+// Singular paths return the selected value or null, other paths return the list of matches.
+func (path *jsonPath) search(value any, keyOrder map[string]int) any {
+	matches := path.path.Query(toYAMLNode(value, keyOrder))
+	if path.singular {
+		if len(matches) == 0 {
+			return nil
+		}
+		return fromYAMLNode(matches[0])
+	}
+	values := make([]any, 0, len(matches))
+	for _, match := range matches {
+		values = append(values, fromYAMLNode(match))
+	}
+	return values
+}
+
+// toYAMLNode converts a decoded JSON value to a YAML node, ordering object keys by keyOrder.
+func toYAMLNode(value any, keyOrder map[string]int) *yaml.Node {
+	switch v := value.(type) {
+	case map[string]any:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range orderedKeys(v, keyOrder) {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, toYAMLNode(v[key], keyOrder))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, toYAMLNode(item, keyOrder))
+		}
+		return node
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case json.Number:
+		tag := "!!float"
+		if _, err := strconv.Atoi(string(v)); err == nil {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(v)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// fromYAMLNode converts a YAML node created by toYAMLNode back to a decoded JSON value.
+func fromYAMLNode(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.MappingNode:
+		object := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			object[node.Content[i].Value] = fromYAMLNode(node.Content[i+1])
+		}
+		return object
+	case yaml.SequenceNode:
+		array := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			array = append(array, fromYAMLNode(item))
+		}
+		return array
+	}
+	switch node.Tag {
+	case "!!str":
+		return node.Value
+	case "!!int", "!!float":
+		return json.Number(node.Value)
+	case "!!bool":
+		return node.Value == "true"
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"bytes"
+	"testing"
+)
+
+// bookstoreDocument is the example document of RFC 9535
+const bookstoreDocument = `{"store": {
+	"book": [
+		{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+		{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+		{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+		{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+	],
+	"bicycle": {"color": "red", "price": 399}
+}}`
+
+func TestJSONPathSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		document   string
+		expression string
+		want       string
+	}{
+		// Examples of RFC 9535, section 1.5
+		{name: "authors of all books", expression: "$.store.book[*].author", want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "all authors", expression: "$..author", want: `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{name: "all things in the store", expression: "$.store.*", want: `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","price":8.99,"isbn":"0-553-21311-3"},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","price":22.99,"isbn":"0-395-19395-8"}],{"price":399,"color":"red"}]`},
+		{name: "prices of everything in the store", expression: "$.store..price", want: `[8.95,12.99,8.99,22.99,399]`},
+		{name: "third book", expression: "$..book[2]", want: `[{"category":"fiction","author":"Herman Melville","title":"Moby Dick","price":8.99,"isbn":"0-553-21311-3"}]`},
+		{name: "third book's author", expression: "$..book[2].author", want: `["Herman Melville"]`},
+		{name: "empty result", expression: "$..book[2].publisher", want: `[]`},
+		{name: "last book", expression: "$..book[-1].title", want: `["The Lord of the Rings"]`},
+		{name: "first two books by union", expression: "$..book[0,1].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{name: "first two books by slice", expression: "$..book[:2].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{name: "books with an isbn", expression: "$..book[?@.isbn].title", want: `["Moby Dick","The Lord of the Rings"]`},
+		{name: "books cheaper than 10", expression: "$..book[?@.price<10].title", want: `["Sayings of the Century","Moby Dick"]`},
+		// Singular queries return the value instead of the list of matches
+		{name: "singular query", expression: "$.store.bicycle.color", want: `"red"`},
+		{name: "singular query with brackets", expression: "$['store']['book'][1]['title']", want: `"Sword of Honour"`},
+		{name: "missing singular query", expression: "$.store.missing", want: `null`},
+		{name: "reversed slice", expression: "$.store.book[::-1].price", want: `[22.99,8.99,12.99,8.95]`},
+		{name: "parenthesized filter", expression: "$.store.book[?(@.category == 'fiction' && @.price > 10)].title", want: `["Sword of Honour","The Lord of the Rings"]`},
+		{name: "negated filter", expression: `$.store.book[?@.price > 20 || !(@.category == "fiction")].title`, want: `["Sayings of the Century","The Lord of the Rings"]`},
+		{name: "negated existence", expression: "$.store.book[?!@.isbn].title", want: `["Sayings of the Century","Sword of Honour"]`},
+		{name: "slice with a zero step", expression: "$.store.book[1:2:0]", want: `[]`},
+		{name: "filter comparing to the root", expression: "$.store.book[?@.price > $.store.book[0].price].title", want: `["Sword of Honour","Moby Dick","The Lord of the Rings"]`},
+		// Function extensions of RFC 9535, section 2.4
+		{name: "length function", expression: "$.store.book[?length(@.title) > 15].title", want: `["Sayings of the Century","The Lord of the Rings"]`},
+		{name: "count function", expression: "$.store[?count(@.*) > 2]", want: `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century","price":8.95},{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},{"category":"fiction","author":"Herman Melville","title":"Moby Dick","price":8.99,"isbn":"0-553-21311-3"},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings","price":22.99,"isbn":"0-395-19395-8"}]]`},
+		{name: "match function", expression: "$.store.book[?match(@.author, 'J.*')].title", want: `["The Lord of the Rings"]`},
+		{name: "search function", expression: "$.store.book[?search(@.title, 'of')].title", want: `["Sayings of the Century","Sword of Honour","The Lord of the Rings"]`},
+		{name: "numbers are kept as received", document: `{"amounts":[12345678901234567890.10,1.50,1e3]}`, expression: "$.amounts[*]", want: `[12345678901234567890.10,1.50,1e3]`},
+		// Object keys are ordered by their first appearance in the document
+		{name: "object members in document order", document: `{"b":1,"a":2,"c":3}`, expression: "$.*", want: `[1,2,3]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := tt.document
+			if document == "" {
+				document = bookstoreDocument
+			}
+			value, keyOrder, err := decodeOrdered(document)
+			if err != nil {
+				t.Fatalf("failed to decode document: %v", err)
+			}
+			path, err := compileJSONPath(tt.expression)
+			if err != nil {
+				t.Fatalf("compileJSONPath() error = %v", err)
+			}
+			var got bytes.Buffer
+			if err := writeOrdered(&got, path.search(value, keyOrder), keyOrder); err != nil {
+				t.Fatalf("failed to encode result: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("search() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileJSONPathInvalid(t *testing.T) {
+	for _, expression := range []string{"store", "$.", "$[", "$.book[?(@.id ==)]", "$.book[?@.price == $.total + 0]", "$['unterminated]", "$.book[?unknown(@.id)]", "$.book[?length(@.*) > 1]"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := compileJSONPath(expression); err == nil {
+				t.Errorf("compileJSONPath(%q) expected an error", expression)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// validatePagination checks that the pagination options name what their type needs.
+func validatePagination(pagination *PaginationOptions) error {
+	if pagination == nil {
+		return nil
+	}
+	switch pagination.Type {
+	case PaginationNextLink, PaginationCursor:
+		if pagination.NextPath == "" {
+			return fmt.Errorf("%s pagination requires next_path", pagination.Type)
+		}
+	case PaginationLinkHeader:
+	case PaginationOffset, PaginationPage:
+	default:
+		return fmt.Errorf("unsupported pagination type: %s", pagination.Type)
+	}
+	if pagination.Param == "" && pagination.Type != PaginationNextLink && pagination.Type != PaginationLinkHeader {
+		return fmt.Errorf("%s pagination requires param", pagination.Type)
+	}
+	if pagination.MaxPages < 0 {
+		return fmt.Errorf("pagination max_pages must not be negative")
+	}
+	return nil
+}
+
+// page is a fetched page of a paginated response.
+type page struct {
+	request  *TransformedRequest
+	response *APIResponse
+	value    any
+	items    []any
+	// args are the arguments the page was requested with
+	args map[string]any
+}
+
+// nextPage is the request for the page that follows a fetched page.
+type nextPage struct {
+	request *TransformedRequest
+	args    map[string]any
+	// value is the cursor, offset, page number or link parameter of the request, or the link itself
+	// for links without a parameter
+	value string
+}
+
+// followPages fetches the pages that follow the first response of a paginated tool and merges
+// their items into the first response. Following stops at the maximum number of pages or
+// response size, or when a page fails, leaving the argument value of the next page in the result.
+func followPages(ctx context.Context, payload *MCPRequest, httpRequest *TransformedRequest, first *APIResponse) *APIResponse {
+	pagination := payload.Options.Pagination
+	if first.Truncated || !first.JSON {
+		return first
+	}
+	document, keyOrder, err := decodeOrdered(first.Text)
+	if err != nil {
+		logger.WarnContext(ctx, "Paginated response is not JSON", "error", err)
+		return first
+	}
+	items, ok := lookupField(document, pagination.ItemsPath).([]any)
+	if !ok {
+		logger.WarnContext(ctx, "Paginated response has no items array", "itemsPath", pagination.ItemsPath)
+		return first
+	}
+	args, err := parseArgs(payload)
+	if err != nil {
+		return first
+	}
+	maxPages := pagination.MaxPages
+	if maxPages == 0 {
+		maxPages = defaultMaxPages
+	}
+	maxSize := int(responseLimit(payload.Options))
+	size := len(first.Text)
+	current := &page{request: httpRequest, response: first, value: document, items: items, args: args}
+	combined := *first
+	for count := 1; ; count++ {
+		next, err := nextPageRequest(payload, current)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to request the next page", "error", err)
+			break
+		}
+		if next == nil {
+			break
+		}
+		var resp *APIResponse
+		if count < maxPages {
+			resp, err = sendRequest(ctx, payload, next.request)
+		}
+		var value any
+		var pageItems []any
+		if resp != nil && resp.StatusCode < http.StatusBadRequest && !resp.Truncated && size+len(resp.Text) <= maxSize {
+			value, _, err = decodeOrdered(resp.Text)
+			if err == nil {
+				pageItems, ok = lookupField(value, pagination.ItemsPath).([]any)
+			}
+		}
+		if pageItems == nil {
+			if resp != nil || err != nil {
+				logger.WarnContext(ctx, "Stopped following pages", "page", count+1, "error", err)
+			}
+			combined.HasMore = true
+			combined.NextPage = next.value
+			combined.NextPageArgument = pageArgument(payload, pagination)
+			break
+		}
+		items = append(items, pageItems...)
+		size += len(resp.Text)
+		current = &page{request: next.request, response: resp, value: value, items: pageItems, args: next.args}
+	}
+	document = setField(document, pagination.ItemsPath, items)
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, document, keyOrder); err != nil {
+		return first
+	}
+	combined.Text = buf.String()
+	combined.Body = buf.Bytes()
+	return &combined
+}
+
+// nextPageRequest creates the request for the page that follows the given page.
+// Returns nil if the page is the last one.
+func nextPageRequest(payload *MCPRequest, current *page) (*nextPage, error) {
+	pagination := payload.Options.Pagination
+	switch pagination.Type {
+	case PaginationNextLink:
+		link, _ := lookupField(current.value, pagination.NextPath).(string)
+		return nextLinkRequest(current, link, pagination.Param)
+	case PaginationLinkHeader:
+		return nextLinkRequest(current, linkHeaderNext(current.response.Header), pagination.Param)
+	case PaginationCursor:
+		cursor := lookupField(current.value, pagination.NextPath)
+		if cursor == nil || cursor == "" {
+			return nil, nil
+		}
+		return nextParamRequest(payload, current, cursor)
+	case PaginationOffset, PaginationPage:
+		if len(current.items) == 0 {
+			return nil, nil
+		}
+		argument := pageArgument(payload, pagination)
+		number, err := strconv.Atoi(fmt.Sprint(current.args[argument]))
+		if err != nil {
+			// The first page starts at offset 0 or page 1
+			number, _ = strconv.Atoi(queryValue(current.request.URL, pagination.Param))
+			if pagination.Type == PaginationPage && number == 0 {
+				number = 1
+			}
+		}
+		if pagination.Type == PaginationOffset {
+			number += len(current.items)
+		} else {
+			number++
+		}
+		return nextParamRequest(payload, current, number)
+	}
+	return nil, fmt.Errorf("unsupported pagination type: %s", pagination.Type)
+}
+
+// nextLinkRequest creates the request for a next page link, which is resolved against the URL of
+// the current page. Links to other hosts are not followed so that credentials are not leaked.
+// The page is identified by the param query parameter of the link, or by the link without param.
+func nextLinkRequest(current *page, link string, param string) (*nextPage, error) {
+	if link == "" {
+		return nil, nil
+	}
+	base, err := url.Parse(current.request.URL)
+	if err != nil {
+		return nil, err
+	}
+	next, err := base.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next page link %s: %v", link, err)
+	}
+	if next.Host != base.Host {
+		return nil, fmt.Errorf("next page link %s is not on host %s", link, base.Host)
+	}
+	headers := make(map[string]string, len(current.request.Headers))
+	for k, v := range current.request.Headers {
+		if k != ContentType {
+			headers[k] = v
+		}
+	}
+	request := &TransformedRequest{Method: http.MethodGet, URL: next.String(), Headers: headers}
+	value := request.URL
+	if param != "" {
+		value = next.Query().Get(param)
+	}
+	return &nextPage{request: request, args: current.args, value: value}, nil
+}
+
+// nextParamRequest creates the request for the next page by setting the pagination argument and
+// transforming the tool request again. Parameters that are not part of the input schema are set on
+// the URL of the current page.
+func nextParamRequest(payload *MCPRequest, current *page, value any) (*nextPage, error) {
+	pagination := payload.Options.Pagination
+	schemaMapping, err := processSchema(payload.Schema)
+	if err != nil {
+		return nil, err
+	}
+	args := make(map[string]any, len(current.args)+1)
+	for k, v := range current.args {
+		args[k] = v
+	}
+	text := fmt.Sprint(value)
+	param, found := findQueryParam(schemaMapping, pagination.Param)
+	if !found {
+		request := *current.request
+		request.URL, err = setQueryValue(current.request.URL, pagination.Param, text)
+		if err != nil {
+			return nil, err
+		}
+		if request.Body != nil {
+			// The body was read by the request of the current page
+			if _, err := request.Body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		return &nextPage{request: &request, args: args, value: text}, nil
+	}
+	args[paramArgument(param)] = value
+	arguments, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	nextPayload := *payload
+	nextPayload.Arguments = string(arguments)
+	request, err := transformMCPRequest(&nextPayload)
+	if err != nil {
+		return nil, err
+	}
+	return &nextPage{request: request, args: args, value: text}, nil
+}
+
+// pageArgument returns the tool argument carrying the pagination parameter.
+func pageArgument(payload *MCPRequest, pagination *PaginationOptions) string {
+	if pagination.Param == "" {
+		return ""
+	}
+	if schemaMapping, err := processSchema(payload.Schema); err == nil {
+		if param, found := findQueryParam(schemaMapping, pagination.Param); found {
+			return paramArgument(param)
+		}
+	}
+	return pagination.Param
+}
+
+func findQueryParam(schemaMapping *SchemaMapping, name string) (Param, bool) {
+	for _, param := range schemaMapping.QueryParameters {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return Param{}, false
+}
+
+func paramArgument(param Param) string {
+	if param.Property != "" {
+		return param.Property
+	}
+	return param.Name
+}
+
+// linkHeaderNext returns the target of the next relation of RFC 8288 Link headers.
+func linkHeaderNext(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, found := strings.Cut(strings.TrimSpace(link), ";")
+			if !found || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func queryValue(rawURL string, name string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(name)
+}
+
+func setQueryValue(rawURL string, name string, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// lookupField returns the value of a dotted field name, or the value itself for an empty name.
+func lookupField(value any, path string) any {
+	if path == "" {
+		return value
+	}
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// setField sets the value of a dotted field name, or replaces the value for an empty name.
+func setField(value any, path string, field any) any {
+	if path == "" {
+		return field
+	}
+	name, rest, _ := strings.Cut(path, ".")
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	object[name] = setField(object[name], rest, field)
+	return object
+}
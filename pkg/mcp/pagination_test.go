@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCallAPIPagination(t *testing.T) {
+	pages := []string{`[{"id":1},{"id":2}]`, `[{"id":3},{"id":4}]`, `[{"id":5}]`, `[]`}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		index := 0
+		switch query.Get("mode") {
+		case "offset":
+			offsets := map[string]int{"": 0, "0": 0, "2": 1, "4": 2}
+			var found bool
+			if index, found = offsets[query.Get("offset")]; !found {
+				index = len(pages) - 1
+			}
+		case "cursor":
+			if cursor := query.Get("cursor"); cursor != "" {
+				index, _ = strconv.Atoi(strings.TrimPrefix(cursor, "c"))
+			}
+		default:
+			if page := query.Get("page"); page != "" {
+				index, _ = strconv.Atoi(page)
+				index--
+			}
+		}
+		if index >= len(pages) {
+			index = len(pages) - 1
+		}
+		w.Header().Set(ContentType, ContentTypeJSON)
+		next := ""
+		if index < 2 {
+			next = fmt.Sprintf("page=%d", index+2)
+			w.Header().Set("Link", fmt.Sprintf(`</items?mode=link&%s>; rel="next", </items?mode=link&page=3>; rel="last"`, next))
+		}
+		switch query.Get("mode") {
+		case "cursor":
+			cursor := "null"
+			if index < 2 {
+				cursor = fmt.Sprintf(`"c%d"`, index+1)
+			}
+			fmt.Fprintf(w, `{"data":%s,"next_cursor":%s}`, pages[index], cursor)
+		case "body":
+			link := ""
+			if next != "" {
+				link = "/items?mode=body&" + next
+			}
+			fmt.Fprintf(w, `{"data":%s,"links":{"next":"%s"}}`, pages[index], link)
+		default:
+			w.Write([]byte(pages[index]))
+		}
+	}))
+	defer backend.Close()
+
+	schema := `{"type":"object","properties":{"query_mode":{"type":"string"},"query_page":{"type":"integer"},"query_cursor":{"type":"string"}}}`
+	tests := []struct {
+		name         string
+		arguments    string
+		pagination   PaginationOptions
+		want         string
+		wantNextPage string
+		wantArgument string
+	}{
+		{
+			name:       "cursor",
+			arguments:  `{"mode":"cursor"}`,
+			pagination: PaginationOptions{Type: PaginationCursor, ItemsPath: "data", NextPath: "next_cursor", Param: "cursor"},
+			want:       `{"data":[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}],"next_cursor":"c1"}`,
+		},
+		{
+			name:       "offset parameter outside the schema",
+			arguments:  `{"mode":"offset"}`,
+			pagination: PaginationOptions{Type: PaginationOffset, Param: "offset"},
+			want:       `[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}]`,
+		},
+		{
+			name:       "page",
+			arguments:  `{"mode":"page","page":1}`,
+			pagination: PaginationOptions{Type: PaginationPage, Param: "page"},
+			want:       `[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}]`,
+		},
+		{
+			name:       "next link in the body",
+			arguments:  `{"mode":"body"}`,
+			pagination: PaginationOptions{Type: PaginationNextLink, ItemsPath: "data", NextPath: "links.next"},
+			want:       `{"data":[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}],"links":{"next":"/items?mode=body\u0026page=2"}}`,
+		},
+		{
+			name:         "link header up to the maximum pages",
+			arguments:    `{"mode":"link"}`,
+			pagination:   PaginationOptions{Type: PaginationLinkHeader, Param: "page", MaxPages: 2},
+			want:         `[{"id":1},{"id":2},{"id":3},{"id":4}]`,
+			wantNextPage: "3",
+			wantArgument: "query_page",
+		},
+		{
+			name:         "next link without param up to the maximum pages",
+			arguments:    `{"mode":"body"}`,
+			pagination:   PaginationOptions{Type: PaginationNextLink, ItemsPath: "data", NextPath: "links.next", MaxPages: 2},
+			want:         `{"data":[{"id":1},{"id":2},{"id":3},{"id":4}],"links":{"next":"/items?mode=body\u0026page=2"}}`,
+			wantNextPage: backend.URL + "/items?mode=body&page=3",
+		},
+		{
+			name:         "cursor up to the maximum pages",
+			arguments:    `{"mode":"cursor"}`,
+			pagination:   PaginationOptions{Type: PaginationCursor, ItemsPath: "data", NextPath: "next_cursor", Param: "cursor", MaxPages: 1},
+			want:         `{"data":[{"id":1},{"id":2}],"next_cursor":"c1"}`,
+			wantNextPage: "c1",
+			wantArgument: "query_cursor",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, ToolOptions{Pagination: &tt.pagination})
+			request.Backend.Target = "/items"
+			request.Schema = schema
+			request.Arguments = tt.arguments
+			resp, err := CallAPI(context.Background(), request)
+			if err != nil {
+				t.Fatalf("CallAPI() error = %v", err)
+			}
+			if resp.Text != tt.want {
+				t.Errorf("CallAPI() text = %s, want %s", resp.Text, tt.want)
+			}
+			if resp.HasMore != (tt.wantNextPage != "") || resp.NextPage != tt.wantNextPage || resp.NextPageArgument != tt.wantArgument {
+				t.Errorf("CallAPI() next page = %v %s=%s, want %s=%s", resp.HasMore, resp.NextPageArgument, resp.NextPage, tt.wantArgument, tt.wantNextPage)
+			}
+		})
+	}
+}
+
+func TestValidatePagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination *PaginationOptions
+		wantErr    bool
+	}{
+		{name: "none"},
+		{name: "link header", pagination: &PaginationOptions{Type: PaginationLinkHeader}},
+		{name: "unsupported type", pagination: &PaginationOptions{Type: "token"}, wantErr: true},
+		{name: "cursor without next path", pagination: &PaginationOptions{Type: PaginationCursor, Param: "cursor"}, wantErr: true},
+		{name: "offset without param", pagination: &PaginationOptions{Type: PaginationOffset}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePagination(tt.pagination); (err != nil) != tt.wantErr {
+				t.Errorf("validatePagination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// responseProjection reduces a JSON response to the parts a tool returns.
+// The mapping expression is applied first, then the included and excluded fields.
+type responseProjection struct {
+	jmesPath *jmesPath
+	jsonPath *jsonPath
+	include  [][]string
+	exclude  [][]string
+}
+
+// newProjection compiles the response mapping and field lists of the tool options.
+// Mapping expressions starting with $ are JSONPath, other expressions are JMESPath.
+// Returns nil if the tool does not project its responses.
+func newProjection(options ToolOptions) (*responseProjection, error) {
+	expression := strings.TrimSpace(options.ResponseMapping)
+	if expression == "" && len(options.IncludeFields) == 0 && len(options.ExcludeFields) == 0 {
+		return nil, nil
+	}
+	projection := &responseProjection{}
+	var err error
+	if strings.HasPrefix(expression, "$") {
+		projection.jsonPath, err = compileJSONPath(expression)
+	} else if expression != "" {
+		projection.jmesPath, err = compileJMESPath(expression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid response mapping %q: %v", expression, err)
+	}
+	if projection.include, err = fieldPaths(options.IncludeFields); err != nil {
+		return nil, fmt.Errorf("invalid included field: %v", err)
+	}
+	if projection.exclude, err = fieldPaths(options.ExcludeFields); err != nil {
+		return nil, fmt.Errorf("invalid excluded field: %v", err)
+	}
+	return projection, nil
+}
+
+// fieldPaths splits dotted field names into their segments.
+func fieldPaths(fields []string) ([][]string, error) {
+	paths := make([][]string, 0, len(fields))
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		if slices.Contains(path, "") {
+			return nil, fmt.Errorf("%q is not a dotted field name", field)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// apply projects a JSON response. Keys of the result follow the order of the response.
+// Returns an error if the response is not JSON or the mapping fails on it.
+func (projection *responseProjection) apply(response string) (string, error) {
+	value, keyOrder, err := decodeOrdered(response)
+	if err != nil {
+		return "", err
+	}
+	if projection.jsonPath != nil {
+		value = projection.jsonPath.search(value, keyOrder)
+	} else if projection.jmesPath != nil {
+		if value, err = projection.jmesPath.search(value); err != nil {
+			return "", err
+		}
+	}
+	if len(projection.include) > 0 {
+		value = includeFields(value, projection.include)
+	}
+	for _, path := range projection.exclude {
+		value = excludeField(value, path)
+	}
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, value, keyOrder); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// projectResponse applies the response mapping and field lists of the tool to a JSON response.
+// Registered tools use the projection compiled when they were registered.
+// Returns the response unchanged if the tool does not project its responses or the response is
+// not JSON, and an error if the mapping is invalid.
+func projectResponse(mcpRequest *MCPRequest, response string) (string, error) {
+	projection := mcpRequest.projection
+	if projection == nil {
+		var err error
+		projection, err = newProjection(mcpRequest.Options)
+		if err != nil || projection == nil {
+			return response, err
+		}
+	}
+	projected, err := projection.apply(response)
+	if err != nil {
+		logger.Warn("Failed to project response, returning it as received", "tool", mcpRequest.ToolName, "error", err)
+		return response, nil
+	}
+	return projected, nil
+}
+
+// includeFields keeps the fields matching the paths. Arrays are traversed transparently and
+// * matches any field.
+func includeFields(value any, paths [][]string) any {
+	switch v := value.(type) {
+	case []any:
+		included := make([]any, 0, len(v))
+		for _, item := range v {
+			included = append(included, includeFields(item, paths))
+		}
+		return included
+	case map[string]any:
+		included := make(map[string]any)
+		for key, child := range v {
+			var rest [][]string
+			whole := false
+			for _, path := range paths {
+				if path[0] != key && path[0] != "*" {
+					continue
+				}
+				if len(path) == 1 {
+					whole = true
+					break
+				}
+				rest = append(rest, path[1:])
+			}
+			if whole {
+				included[key] = child
+			} else if rest != nil {
+				switch child.(type) {
+				case map[string]any, []any:
+					included[key] = includeFields(child, rest)
+				}
+			}
+		}
+		return included
+	}
+	return value
+}
+
+// excludeField removes the fields matching the path. Arrays are traversed transparently and
+// * matches any field.
+func excludeField(value any, path []string) any {
+	switch v := value.(type) {
+	case []any:
+		excluded := make([]any, 0, len(v))
+		for _, item := range v {
+			excluded = append(excluded, excludeField(item, path))
+		}
+		return excluded
+	case map[string]any:
+		excluded := make(map[string]any, len(v))
+		for key, child := range v {
+			if path[0] != key && path[0] != "*" {
+				excluded[key] = child
+			} else if len(path) > 1 {
+				excluded[key] = excludeField(child, path[1:])
+			}
+		}
+		return excluded
+	}
+	return value
+}
+
+// decodeOrdered decodes a JSON value keeping numbers as json.Number.
+// Returns the value and the rank of each object key by its first appearance in the document.
+func decodeOrdered(data string) (any, map[string]int, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	keyOrder := make(map[string]int)
+	value, err := decodeOrderedValue(decoder, keyOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, keyOrder, nil
+}
+
+func decodeOrderedValue(decoder *json.Decoder, keyOrder map[string]int) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := make(map[string]any)
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			if _, exists := keyOrder[key]; !exists {
+				keyOrder[key] = len(keyOrder)
+			}
+			if object[key], err = decodeOrderedValue(decoder, keyOrder); err != nil {
+				return nil, err
+			}
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			item, err := decodeOrderedValue(decoder, keyOrder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// orderedKeys returns the keys of an object by their rank, followed by the keys without a rank in
+// alphabetical order.
+func orderedKeys(object map[string]any, keyOrder map[string]int) []string {
+	keys := sortedKeys(object)
+	slices.SortStableFunc(keys, func(a, b string) int {
+		rankA, okA := keyOrder[a]
+		rankB, okB := keyOrder[b]
+		switch {
+		case okA && okB:
+			return rankA - rankB
+		case okA:
+			return -1
+		case okB:
+			return 1
+		}
+		return 0
+	})
+	return keys
+}
+
+// writeOrdered encodes a value as JSON, writing object keys in the order of orderedKeys.
+func writeOrdered(buf *bytes.Buffer, value any, keyOrder map[string]int) error {
+	switch v := value.(type) {
+	case map[string]any:
+		buf.WriteByte('{')
+		for i, key := range orderedKeys(v, keyOrder) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			if err := writeOrdered(buf, v[key], keyOrder); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeOrdered(buf, item, keyOrder); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import "testing"
+
+func TestProjectResponse(t *testing.T) {
+	response := `{"items":[{"status":"active","id":1,"name":"alpha","owner":{"id":9,"email":"a@example.com"}},{"status":"inactive","id":2,"name":"beta","owner":{"id":8,"email":"b@example.com"}}],"total":2}`
+	tests := []struct {
+		name     string
+		response string
+		options  ToolOptions
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "no projection",
+			response: response,
+			want:     response,
+		},
+		{
+			name:     "jmespath keeps the response key order",
+			response: response,
+			options:  ToolOptions{ResponseMapping: "items[*].{name: name, id: id, status: status}"},
+			want:     `[{"status":"active","id":1,"name":"alpha"},{"status":"inactive","id":2,"name":"beta"}]`,
+		},
+		{
+			name:     "jsonpath",
+			response: response,
+			options:  ToolOptions{ResponseMapping: "$.items[?(@.status == 'active')].owner.email"},
+			want:     `["a@example.com"]`,
+		},
+		{
+			name:     "included fields",
+			response: response,
+			options:  ToolOptions{IncludeFields: []string{"items.id", "items.owner.id", "total"}},
+			want:     `{"items":[{"id":1,"owner":{"id":9}},{"id":2,"owner":{"id":8}}],"total":2}`,
+		},
+		{
+			name:     "excluded fields",
+			response: response,
+			options:  ToolOptions{ExcludeFields: []string{"items.owner.email", "total"}},
+			want:     `{"items":[{"status":"active","id":1,"name":"alpha","owner":{"id":9}},{"status":"inactive","id":2,"name":"beta","owner":{"id":8}}]}`,
+		},
+		{
+			name:     "mapping then excluded wildcard fields",
+			response: response,
+			options:  ToolOptions{ResponseMapping: "items[0]", ExcludeFields: []string{"*.id"}},
+			want:     `{"status":"active","id":1,"name":"alpha","owner":{"email":"a@example.com"}}`,
+		},
+		{
+			name:     "numbers are kept as received",
+			response: `{"amount":12345678901234567890.10,"other":1}`,
+			options:  ToolOptions{IncludeFields: []string{"amount"}},
+			want:     `{"amount":12345678901234567890.10}`,
+		},
+		{
+			name:     "response that is not JSON is returned as received",
+			response: "plain text",
+			options:  ToolOptions{ResponseMapping: "items"},
+			want:     "plain text",
+		},
+		{
+			name:     "invalid mapping",
+			response: response,
+			options:  ToolOptions{ResponseMapping: "items[*"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid field",
+			response: response,
+			options:  ToolOptions{IncludeFields: []string{"items..id"}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectResponse(&MCPRequest{ToolName: "listItems", Options: tt.options}, tt.response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("projectResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want && !tt.wantErr {
+				t.Errorf("projectResponse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisteredToolProjection(t *testing.T) {
+	registry := NewToolRegistry()
+	tool := &ToolDefinition{
+		Name:        "listItems",
+		InputSchema: []byte(`{"type":"object","properties":{}}`),
+		Backend:     &BackendInfo{Endpoint: "http://localhost:9090", Target: "/items", Verb: "GET"},
+		Options:     ToolOptions{ResponseMapping: "items[*].id"},
+	}
+	if err := registry.Register(tool); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	request := tool.NewMCPRequest("{}")
+	if request.projection == nil || request.projection != tool.projection {
+		t.Fatal("NewMCPRequest() does not carry the projection compiled by Register()")
+	}
+	// The compiled projection is used instead of compiling the options again
+	request.Options.ResponseMapping = "items[*"
+	got, err := projectResponse(request, `{"items":[{"id":1},{"id":2}]}`)
+	if err != nil || got != "[1,2]" {
+		t.Errorf("projectResponse() = %s, %v, want [1,2]", got, err)
+	}
+}
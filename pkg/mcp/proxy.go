@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"mcp-server/pkg/service"
+	"net/http"
+	"net/url"
+)
+
+// proxySelector selects the outbound proxy of requests to the underlying APIs.
+type proxySelector struct {
+	// proxyURL is the proxy of all backends, nil to use the environment
+	proxyURL *url.URL
+	// noProxy matches the hosts reached directly
+	noProxy *hostPatterns
+	// backends maps the hosts of proxy backends to their names
+	backends *hostPatterns
+	// backendURLs are the proxies of the proxy backends, nil for direct backends
+	backendURLs map[string]*url.URL
+	// fromEnvironment returns the proxy of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables
+	fromEnvironment func(*http.Request) (*url.URL, error)
+}
+
+// newProxySelector creates the proxy selector of the config, whose proxy URLs are validated with
+// the config.
+func newProxySelector(proxy service.Proxy) *proxySelector {
+	selector := &proxySelector{
+		noProxy:         newHostPatterns(),
+		backends:        newHostPatterns(),
+		backendURLs:     make(map[string]*url.URL, len(proxy.Backends)),
+		fromEnvironment: http.ProxyFromEnvironment,
+	}
+	if proxy.URL != "" {
+		selector.proxyURL, _ = url.Parse(proxy.URL)
+		logger.Info("Calling backends through outbound proxy", "proxy", selector.proxyURL.Redacted())
+	}
+	for _, host := range proxy.NoProxy {
+		selector.noProxy.add(host, host)
+	}
+	for name, backend := range proxy.Backends {
+		var backendURL *url.URL
+		if !backend.Direct {
+			backendURL, _ = url.Parse(backend.URL)
+		}
+		selector.backendURLs[name] = backendURL
+		for _, host := range backend.Hosts {
+			selector.backends.add(host, name)
+		}
+	}
+	return selector
+}
+
+// proxy returns the proxy of a request, or nil to send it directly. The proxy backend of the host
+// takes precedence over NoProxy, which takes precedence over the configured proxy and the
+// environment.
+func (selector *proxySelector) proxy(request *http.Request) (*url.URL, error) {
+	if name := selector.backends.match(request.URL.Host); name != "" {
+		return selector.backendURLs[name], nil
+	}
+	if selector.noProxy.match(request.URL.Host) != "" {
+		return nil, nil
+	}
+	if selector.proxyURL != nil {
+		return selector.proxyURL, nil
+	}
+	return selector.fromEnvironment(request)
+}
@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ToolRegistry holds the tools known to the service, keyed by tool name.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*ToolDefinition
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{
+		tools: make(map[string]*ToolDefinition),
+	}
+}
+
+// LoadToolRegistry loads the tool definitions from the JSON files in the given directory.
+// Each file contains either a single tool definition or an array of tool definitions.
+func LoadToolRegistry(dir string) (*ToolRegistry, error) {
+	registry := NewToolRegistry()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		tools, err := readToolDefinitions(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tool definitions from %s: %v", file, err)
+		}
+		for _, tool := range tools {
+			if err := registry.Register(tool); err != nil {
+				return nil, fmt.Errorf("invalid tool definition in %s: %v", file, err)
+			}
+		}
+	}
+	logger.Info("Loaded tool definitions", "directory", dir, "tools", registry.Len())
+	return registry, nil
+}
+
+func readToolDefinitions(file string) ([]*ToolDefinition, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var tools []*ToolDefinition
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &tools)
+	} else {
+		var tool ToolDefinition
+		err = json.Unmarshal(trimmed, &tool)
+		tools = append(tools, &tool)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tools, nil
+}
+
+// Register adds a tool definition to the registry after validating it.
+func (registry *ToolRegistry) Register(tool *ToolDefinition) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name is required")
+	}
+	var inputSchema MCPInputSchema
+	if len(tool.InputSchema) == 0 {
+		return fmt.Errorf("input schema of tool %s is required", tool.Name)
+	}
+	if err := json.Unmarshal(tool.InputSchema, &inputSchema); err != nil {
+		return fmt.Errorf("invalid input schema of tool %s: %v", tool.Name, err)
+	}
+	if inputSchema.Type != "object" {
+		return fmt.Errorf("input schema of tool %s must be of type object", tool.Name)
+	}
+	if len(tool.OutputSchema) > 0 {
+		var outputSchema MCPInputSchema
+		if err := json.Unmarshal(tool.OutputSchema, &outputSchema); err != nil {
+			return fmt.Errorf("invalid output schema of tool %s: %v", tool.Name, err)
+		}
+		if outputSchema.Type != "object" {
+			return fmt.Errorf("output schema of tool %s must be of type object", tool.Name)
+		}
+	}
+	patterns, err := compileSchemaPatterns(tool.InputSchema, tool.OutputSchema)
+	if err != nil {
+		return fmt.Errorf("invalid schema of tool %s: %v", tool.Name, err)
+	}
+	projection, err := newProjection(tool.Options)
+	if err != nil {
+		return fmt.Errorf("invalid options of tool %s: %v", tool.Name, err)
+	}
+	if err := validatePagination(tool.Options.Pagination); err != nil {
+		return fmt.Errorf("invalid options of tool %s: %v", tool.Name, err)
+	}
+	if err := validateTLSProfile(tool.Options.TLSProfile); err != nil {
+		return fmt.Errorf("invalid options of tool %s: %v", tool.Name, err)
+	}
+	if err := ValidateMCPRequest(tool.NewMCPRequest("{}")); err != nil {
+		return fmt.Errorf("invalid tool %s: %v", tool.Name, err)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, exists := registry.tools[tool.Name]; exists {
+		return fmt.Errorf("duplicate tool name: %s", tool.Name)
+	}
+	tool.projection = projection
+	tool.patterns = patterns
+	registry.tools[tool.Name] = tool
+	return nil
+}
+
+// Get returns the tool definition with the given name.
+func (registry *ToolRegistry) Get(name string) (*ToolDefinition, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	tool, ok := registry.tools[name]
+	return tool, ok
+}
+
+// List returns the registered tool definitions sorted by name.
+func (registry *ToolRegistry) List() []*ToolDefinition {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	tools := make([]*ToolDefinition, 0, len(registry.tools))
+	for _, tool := range registry.tools {
+		tools = append(tools, tool)
+	}
+	slices.SortFunc(tools, func(a, b *ToolDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return tools
+}
+
+// Len returns the number of registered tools.
+func (registry *ToolRegistry) Len() int {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return len(registry.tools)
+}
+
+// Resolve fills in the schema and API details of a request that only carries the tool name
+// and arguments from the registered tool definition.
+// Requests that carry their own API details are left untouched.
+func (registry *ToolRegistry) Resolve(mcpRequest *MCPRequest) {
+	if mcpRequest.Schema != "" || mcpRequest.API.Endpoint != "" || mcpRequest.Backend.Endpoint != "" {
+		return
+	}
+	tool, ok := registry.Get(mcpRequest.ToolName)
+	if !ok {
+		return
+	}
+	auth := mcpRequest.API.Auth
+	*mcpRequest = *tool.NewMCPRequest(mcpRequest.Arguments)
+	if mcpRequest.API.Auth == "" {
+		mcpRequest.API.Auth = auth
+	}
+}
+
+// NewMCPRequest creates a request to call the tool with the given arguments.
+func (tool *ToolDefinition) NewMCPRequest(arguments string) *MCPRequest {
+	mcpRequest := &MCPRequest{
+		ToolName:     tool.Name,
+		Arguments:    arguments,
+		Schema:       string(tool.InputSchema),
+		OutputSchema: string(tool.OutputSchema),
+		IsProxy:      tool.IsProxy,
+		Options:      tool.Options,
+		projection:   tool.projection,
+		patterns:     tool.patterns,
+	}
+	if tool.API != nil {
+		mcpRequest.API = *tool.API
+	}
+	if tool.Backend != nil {
+		mcpRequest.Backend = *tool.Backend
+	}
+	return mcpRequest
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testToolDefinition = `{
+	"name": "getOrder",
+	"description": "Get an order",
+	"inputSchema": {"type": "object", "properties": {"path_id": {"type": "string"}}},
+	"backend": {"endpoint": "http://localhost:9090", "target": "/orders/{id}", "verb": "GET"}
+}`
+
+func TestLoadToolRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantTools []string
+		wantErr   bool
+	}{
+		{
+			name: "single and array definitions",
+			files: map[string]string{
+				"order.json": testToolDefinition,
+				"list.json": `[{
+					"name": "listOrders",
+					"inputSchema": {"type": "object", "properties": {}},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}
+				}]`,
+				"README.md": "ignored",
+			},
+			wantTools: []string{"getOrder", "listOrders"},
+		},
+		{
+			name: "duplicate tool names",
+			files: map[string]string{
+				"a.json": testToolDefinition,
+				"b.json": testToolDefinition,
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing backend details",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "object"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "input schema is not an object",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "string"},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid response mapping",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "object"}, "options": {"response_mapping": "items[*"},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid input schema pattern",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "object", "properties": {"id": {"type": "string", "pattern": "[a-z"}}},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid output schema pattern properties",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "object"}, "outputSchema": {"type": "object", "patternProperties": {"(": {}}},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "output schema is not an object",
+			files: map[string]string{
+				"a.json": `{"name": "broken", "inputSchema": {"type": "object"}, "outputSchema": {"type": "array"},
+					"backend": {"endpoint": "http://localhost:9090", "target": "/orders", "verb": "GET"}}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			registry, err := LoadToolRegistry(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadToolRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for _, tool := range registry.List() {
+				got = append(got, tool.Name)
+			}
+			if len(got) != len(tt.wantTools) {
+				t.Fatalf("List() = %v, want %v", got, tt.wantTools)
+			}
+			for i := range got {
+				if got[i] != tt.wantTools[i] {
+					t.Errorf("List() = %v, want %v", got, tt.wantTools)
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "order.json"), []byte(testToolDefinition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry, err := LoadToolRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mcpRequest := &MCPRequest{ToolName: "getOrder", Arguments: `{"id":"1"}`, API: APIInfo{Auth: "Authorization: Bearer token"}}
+	registry.Resolve(mcpRequest)
+	if mcpRequest.Backend.Target != "/orders/{id}" || mcpRequest.Schema == "" {
+		t.Errorf("Resolve() did not fill in the tool definition: %+v", mcpRequest)
+	}
+	if mcpRequest.Arguments != `{"id":"1"}` || mcpRequest.API.Auth != "Authorization: Bearer token" {
+		t.Errorf("Resolve() did not keep the request arguments and auth: %+v", mcpRequest)
+	}
+
+	explicit := &MCPRequest{ToolName: "getOrder", Backend: BackendInfo{Endpoint: "http://other", Target: "/x", Verb: "GET"}}
+	registry.Resolve(explicit)
+	if explicit.Backend.Endpoint != "http://other" {
+		t.Errorf("Resolve() overwrote the request API details: %+v", explicit)
+	}
+}
@@ -2,44 +2,232 @@ package mcp
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"mcp-server/pkg/service"
 	"net/http"
-	"strings"
+	"time"
 )
 
 var logger = service.GetLogger()
 
+// APIResponse is the response of the underlying API of a tool.
+type APIResponse struct {
+	StatusCode  int
+	ContentType string
+	URL         string
+	// Body is the response body as received
+	Body []byte
+	// Text is the response body converted to JSON where possible
+	Text string
+	// JSON reports that Text is JSON, as received or converted from another format
+	JSON bool
+	// Truncated reports that the body was cut at the maximum response size
+	Truncated bool
+	// Size is the size of a truncated body announced by the API, or 0 if it is not known
+	Size int64
+	// Header holds the response headers of the API
+	Header http.Header
+	// NextPage is the value of the pagination argument that continues a paginated result, empty
+	// if there are no more pages or the value is not known
+	NextPage string
+	// NextPageArgument is the argument carrying NextPage, empty if NextPage is the link of the next
+	// page
+	NextPageArgument string
+	// HasMore reports that pages were left after the maximum number of pages
+	HasMore bool
+}
+
+// CallUnderlyingAPI calls the underlying API of the tool.
+// Returns the processed response body and the status code, which is the status code of NewToolError
+// for failed calls, such as 400 for invalid arguments.
 func CallUnderlyingAPI(ctx context.Context, payload *MCPRequest) (string, int, error) {
-	httpClient := InitHttpClient()
+	resp, err := CallAPI(ctx, payload)
+	if err != nil {
+		return "", ErrorStatusCode(err), err
+	}
+	return resp.Text, resp.StatusCode, nil
+}
+
+// CallAPI calls the underlying API of the tool.
+// Returns the response of the API or an error if the API could not be called.
+func CallAPI(ctx context.Context, payload *MCPRequest) (*APIResponse, error) {
+	// The timeout covers all pages of the call and reading their bodies
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout(payload.Options))
+	defer cancel()
 	httpRequest, err := transformMCPRequest(payload)
 	if err != nil {
 		logger.ErrorContext(ctx, "Failed to transform request", "error", err)
-		return "", http.StatusInternalServerError, err
+		return nil, err
 	}
-	request, err := httpClient.GenerateRequest(httpRequest)
+	apiResponse, err := sendRequest(ctx, payload, httpRequest)
 	if err != nil {
-		logger.ErrorContext(ctx, "Failed to generate request", "error", err)
-		return "", http.StatusInternalServerError, err
+		return nil, err
 	}
-	resp, err := httpClient.DoRequest(request)
+	if apiResponse.StatusCode >= http.StatusBadRequest {
+		return apiResponse, nil
+	}
+	if payload.Options.Pagination != nil {
+		apiResponse = followPages(ctx, payload, httpRequest, apiResponse)
+	}
+	if apiResponse.JSON && apiResponse.Text != "" {
+		apiResponse.Text, err = projectResponse(payload, apiResponse.Text)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to project response", "error", err)
+			return nil, err
+		}
+	}
+	// Only JSON responses can match the output schema, and truncated responses are expected to
+	// miss parts of it
+	if validatesOutput(apiResponse) {
+		if err := validateOutput(payload, apiResponse.Text); err != nil {
+			logger.WarnContext(ctx, "Response does not match the output schema", "error", err)
+			if payload.Options.StrictOutput {
+				return nil, err
+			}
+		}
+	}
+	return apiResponse, nil
+}
+
+// validatesOutput reports whether a response is validated against the output schema.
+func validatesOutput(resp *APIResponse) bool {
+	return !resp.Truncated && resp.JSON
+}
+
+// sendRequest sends a transformed request to the underlying API and reads its response.
+// Returns the response with its body converted to JSON where possible.
+func sendRequest(ctx context.Context, payload *MCPRequest, httpRequest *TransformedRequest) (*APIResponse, error) {
+	resp, err := doRequest(ctx, payload, httpRequest)
 	if err != nil {
-		logger.ErrorContext(ctx, "Failed to send request", "error", err)
-		return "", http.StatusInternalServerError, err
+		return nil, err
 	}
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	contentType := resp.Header.Get(ContentType)
+	maxBinarySize := binaryResponseLimit(payload.Options)
+	maxSize := responseLimit(payload.Options)
+	// Reading stops past the limit, responses without a content type may still turn out binary
+	readLimit := maxSize
+	if isBinaryResponse(responseMediaType(contentType)) {
+		if resp.ContentLength > maxBinarySize {
+			return nil, fmt.Errorf("binary response of %d bytes exceeds the maximum size of %d bytes", resp.ContentLength, maxBinarySize)
+		}
+		readLimit = maxBinarySize
+	} else if contentType == "" {
+		readLimit = max(maxSize, maxBinarySize)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, readLimit+1))
 	if err != nil {
 		logger.ErrorContext(ctx, "Failed to read response body", "error", err)
-		return "", http.StatusInternalServerError, err
+		return nil, err
 	}
-	response := string(body)
-	if strings.Contains(resp.Header.Get(ContentType), ContentTypeJSON) {
-		response, err = processJsonResponse(response)
-		if err != nil {
-			logger.ErrorContext(ctx, "Failed to process JSON response", "error", err)
-			return "", http.StatusInternalServerError, err
+	if contentType == "" && len(body) > 0 {
+		contentType = http.DetectContentType(body)
+	}
+	apiResponse := &APIResponse{
+		StatusCode:  resp.StatusCode,
+		ContentType: contentType,
+		URL:         httpRequest.URL,
+		Header:      resp.Header,
+	}
+	if mediaType := responseMediaType(contentType); isBinaryResponse(mediaType) {
+		if int64(len(body)) > maxBinarySize {
+			return nil, fmt.Errorf("binary response exceeds the maximum size of %d bytes", maxBinarySize)
+		}
+		apiResponse.Body = body
+		apiResponse.Text = binaryResponseText(mediaType, body)
+		return apiResponse, nil
+	}
+	if int64(len(body)) > maxSize {
+		logger.WarnContext(ctx, "Response exceeds the maximum size and is truncated", "maxSize", maxSize, "contentLength", resp.ContentLength)
+		body = truncateResponse(body, contentType, payload.Options, int(maxSize))
+		apiResponse.Truncated = true
+		if resp.ContentLength > 0 {
+			apiResponse.Size = resp.ContentLength
+		}
+	}
+	apiResponse.Body = body
+	apiResponse.Text, apiResponse.JSON, err = processResponse(body, contentType, payload.Options)
+	if err != nil && apiResponse.Truncated {
+		// JSON responses that could not be cut at an element boundary are returned as text
+		apiResponse.Text = string(body)
+	} else if err != nil {
+		logger.ErrorContext(ctx, "Failed to process response", "error", err)
+		return nil, err
+	}
+	return apiResponse, nil
+}
+
+// requestTimeout returns the overall timeout of a call to the underlying API of the tool.
+func requestTimeout(options ToolOptions) time.Duration {
+	if options.Timeout > 0 {
+		return time.Duration(options.Timeout) * time.Second
+	}
+	if config := service.GetConfig(); config != nil && config.Http.Timeout > 0 {
+		return time.Duration(config.Http.Timeout) * time.Second
+	}
+	return defaultRequestTimeout * time.Second
+}
+
+// responseLimit returns the maximum size of text responses of the tool.
+func responseLimit(options ToolOptions) int64 {
+	if options.MaxResponseSize > 0 {
+		return options.MaxResponseSize
+	}
+	if config := service.GetConfig(); config != nil && config.Http.MaxResponseSize > 0 {
+		return config.Http.MaxResponseSize
+	}
+	return defaultMaxResponseSize
+}
+
+// binaryResponseLimit returns the maximum size of binary responses of the tool.
+func binaryResponseLimit(options ToolOptions) int64 {
+	if options.MaxBinarySize > 0 {
+		return options.MaxBinarySize
+	}
+	if config := service.GetConfig(); config != nil && config.Http.MaxBinaryResponseSize > 0 {
+		return config.Http.MaxBinaryResponseSize
+	}
+	return defaultMaxBinaryResponseSize
+}
+
+// ErrorStatusCode returns the HTTP status code for an error of calling the underlying API.
+func ErrorStatusCode(err error) int {
+	return NewToolError(err).StatusCode()
+}
+
+// ValidateMCPRequest checks that the request carries the details needed to call the underlying API.
+func ValidateMCPRequest(mcpRequest *MCPRequest) error {
+	if mcpRequest.ToolName == "" {
+		return fmt.Errorf("tool name is required")
+	} else if mcpRequest.Arguments == "" {
+		return fmt.Errorf("arguments are required")
+	} else if mcpRequest.Schema == "" {
+		logger.Warn("Input schema is not provided")
+	}
+	if mcpRequest.IsProxy {
+		if mcpRequest.API.APIName == "" {
+			logger.Warn("API name is not provided")
+		}
+		if mcpRequest.API.Endpoint == "" {
+			return fmt.Errorf("API endpoint is required")
+		} else if mcpRequest.API.Context == "" {
+			return fmt.Errorf("API context is required")
+		} else if mcpRequest.API.Version == "" {
+			return fmt.Errorf("API version is required")
+		} else if mcpRequest.API.Path == "" {
+			return fmt.Errorf("resource path is required")
+		} else if mcpRequest.API.Verb == "" {
+			return fmt.Errorf("HTTP verb is required")
+		}
+	} else {
+		if mcpRequest.Backend.Endpoint == "" {
+			return fmt.Errorf("backend endpoint is required")
+		} else if mcpRequest.Backend.Target == "" {
+			return fmt.Errorf("backend target is required")
+		} else if mcpRequest.Backend.Verb == "" {
+			return fmt.Errorf("backend verb is required")
 		}
 	}
-	return response, resp.StatusCode, nil
+	return nil
 }
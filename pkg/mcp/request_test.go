@@ -0,0 +1,299 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBackendRequest(endpoint string, options ToolOptions) *MCPRequest {
+	return &MCPRequest{
+		ToolName:  "getFile",
+		Arguments: "{}",
+		Backend:   BackendInfo{Endpoint: endpoint, Target: "/file", Verb: "GET"},
+		Options:   options,
+	}
+}
+
+func TestCallAPIBinaryResponse(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("type") {
+		case "sniff":
+			// No content type, which is detected from the body
+			w.Header()["Content-Type"] = nil
+		default:
+			w.Header().Set(ContentType, "image/png")
+		}
+		w.Write(png)
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name     string
+		query    string
+		options  ToolOptions
+		wantType string
+		wantErr  bool
+	}{
+		{name: "image", wantType: "image/png"},
+		{name: "sniffed image", query: "?type=sniff", wantType: "image/png"},
+		{name: "too large", options: ToolOptions{MaxBinarySize: 4}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, tt.options)
+			request.Backend.Target = "/file" + tt.query
+			resp, err := CallAPI(context.Background(), request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CallAPI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if string(resp.Body) != string(png) || resp.ContentType != tt.wantType {
+				t.Errorf("CallAPI() = %q %s, want the PNG body as %s", resp.Body, resp.ContentType, tt.wantType)
+			}
+			if !strings.HasPrefix(resp.Text, `{"mimeType":"image/png","data":"iVBORw0KGgo`) {
+				t.Errorf("CallAPI() text = %s", resp.Text)
+			}
+		})
+	}
+}
+
+func TestCallAPIOutputSchema(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image":
+			w.Header().Set(ContentType, "image/png")
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		case "/text":
+			w.Header().Set(ContentType, "text/plain")
+			w.Write([]byte("ok"))
+		case "/xml":
+			w.Header().Set(ContentType, ContentTypeXML)
+			w.Write([]byte("<order><id>7</order>"))
+		default:
+			w.Header().Set(ContentType, ContentTypeJSON)
+			w.Write([]byte(`{"id":"7","total":12.5}`))
+		}
+	}))
+	defer backend.Close()
+
+	strictSchema := `{"type":"object","properties":{"id":{"type":"integer"}}}`
+	tests := []struct {
+		name         string
+		target       string
+		outputSchema string
+		options      ToolOptions
+		wantErr      bool
+		wantContent  string
+	}{
+		{
+			name:         "matching response",
+			outputSchema: `{"type":"object","required":["id"],"properties":{"id":{"type":"string"},"total":{"type":"number"}}}`,
+			wantContent:  `{"id":"7","total":12.5}`,
+		},
+		{
+			name:         "mismatch is only logged",
+			outputSchema: strictSchema,
+			wantContent:  `{"id":"7","total":12.5}`,
+		},
+		{
+			name:         "mismatch with strict output",
+			outputSchema: strictSchema,
+			options:      ToolOptions{StrictOutput: true},
+			wantErr:      true,
+		},
+		{
+			name:         "binary responses are not validated",
+			target:       "/image",
+			outputSchema: strictSchema,
+			options:      ToolOptions{StrictOutput: true},
+		},
+		{
+			name:         "text responses are not validated",
+			target:       "/text",
+			outputSchema: strictSchema,
+			options:      ToolOptions{StrictOutput: true},
+		},
+		{
+			name:         "responses that failed to convert are not validated",
+			target:       "/xml",
+			outputSchema: strictSchema,
+			options:      ToolOptions{StrictOutput: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, tt.options)
+			if tt.target != "" {
+				request.Backend.Target = tt.target
+			}
+			request.OutputSchema = tt.outputSchema
+			resp, err := CallAPI(context.Background(), request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CallAPI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				var outputErr *OutputValidationError
+				if !errors.As(err, &outputErr) || ErrorStatusCode(err) != http.StatusBadGateway {
+					t.Errorf("CallAPI() error = %v, want an output validation error", err)
+				}
+				return
+			}
+			if result := NewCallToolResult(resp); tt.wantContent != "" && string(result.StructuredContent) != tt.wantContent {
+				t.Errorf("NewCallToolResult() structuredContent = %s", result.StructuredContent)
+			}
+		})
+	}
+}
+
+func TestCallAPIResponseMapping(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, r.URL.Query().Get("type"))
+		if r.URL.Query().Get("status") != "" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(`{"items":[{"id":1,"name":"alpha","status":"active","notes":"long"}],"next":"abc"}`))
+	}))
+	defer backend.Close()
+
+	options := ToolOptions{ResponseMapping: "items[*].{id: id, name: name, status: status}"}
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "json", query: "?type=application/json", want: `[{"id":1,"name":"alpha","status":"active"}]`},
+		{name: "error responses are not mapped", query: "?type=application/json&status=404", want: `{"items":[{"id":1,"name":"alpha","status":"active","notes":"long"}],"next":"abc"}`},
+		{name: "text responses are not mapped", query: "?type=text/plain", want: `{"items":[{"id":1,"name":"alpha","status":"active","notes":"long"}],"next":"abc"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, options)
+			request.Backend.Target = "/items" + tt.query
+			resp, err := CallAPI(context.Background(), request)
+			if err != nil {
+				t.Fatalf("CallAPI() error = %v", err)
+			}
+			if resp.Text != tt.want {
+				t.Errorf("CallAPI() text = %s, want %s", resp.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallAPIResponseSize(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeJSON)
+		w.Write([]byte(`{"items":[{"id":1},{"id":2},{"id":3}],"next":"abc"}`))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name          string
+		options       ToolOptions
+		want          string
+		wantTruncated bool
+	}{
+		{name: "within the limit", want: `{"items":[{"id":1},{"id":2},{"id":3}],"next":"abc"}`},
+		{name: "truncated", options: ToolOptions{MaxResponseSize: 30}, want: `{"items":[{"id":1},{"id":2}]}`, wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, tt.options)
+			request.OutputSchema = `{"type":"object","required":["next"]}`
+			request.Options.StrictOutput = true
+			resp, err := CallAPI(context.Background(), request)
+			if err != nil {
+				t.Fatalf("CallAPI() error = %v", err)
+			}
+			if resp.Text != tt.want || resp.Truncated != tt.wantTruncated {
+				t.Errorf("CallAPI() = %s truncated %v, want %s truncated %v", resp.Text, resp.Truncated, tt.want, tt.wantTruncated)
+			}
+			result := NewCallToolResult(resp)
+			if tt.wantTruncated && (len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "truncated to 29 bytes of 51 bytes")) {
+				t.Errorf("NewCallToolResult() content = %+v, want a truncation notice", result.Content)
+			}
+		})
+	}
+}
+
+func TestCallAPITimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "body" {
+			w.Header().Set(ContentType, ContentTypeJSON)
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		// Hang until the call gives up
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		query    string
+		options  ToolOptions
+		wantType string
+	}{
+		{name: "response headers", ctx: context.Background(), options: ToolOptions{ResponseHeaderTimeout: 1, Retry: &RetryOptions{MaxAttempts: 1}}, wantType: ErrorTypeBackendTimeout},
+		{name: "overall", ctx: context.Background(), query: "?slow=body", options: ToolOptions{Timeout: 1}, wantType: ErrorTypeBackendTimeout},
+		{name: "cancelled by the client", ctx: cancelled, wantType: ErrorTypeCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, tt.options)
+			request.Backend.Target = "/slow" + tt.query
+			start := time.Now()
+			_, err := CallAPI(tt.ctx, request)
+			if err == nil {
+				t.Fatal("CallAPI() expected an error")
+			}
+			if got := NewToolError(err).Type; got != tt.wantType {
+				t.Errorf("CallAPI() error = %v of type %s, want %s", err, got, tt.wantType)
+			}
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("CallAPI() took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestTransportCache(t *testing.T) {
+	cache := newTransportCache(2)
+	created := 0
+	get := func(seconds int) *http.Transport {
+		key := transportKey{timeouts: transportTimeouts{connect: time.Duration(seconds) * time.Second}}
+		return cache.get(key, func() *http.Transport {
+			created++
+			return &http.Transport{}
+		})
+	}
+	first := get(1)
+	get(2)
+	if get(1) != first {
+		t.Errorf("get() created a new transport for a cached key")
+	}
+	// The transport of 2s is the least recently used and is evicted
+	get(3)
+	get(1)
+	get(2)
+	if created != 4 {
+		t.Errorf("created %d transports, want 4", created)
+	}
+	if len(cache.transports) != 2 || cache.recent.Len() != 2 {
+		t.Errorf("cached %d transports, want 2", len(cache.transports))
+	}
+}
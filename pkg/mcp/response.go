@@ -0,0 +1,317 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Response formats converted to JSON
+const (
+	formatJSON = "json"
+	formatXML  = "xml"
+	formatCSV  = "csv"
+	formatYAML = "yaml"
+)
+
+var responseFormats = map[string]string{
+	ContentTypeJSON:      formatJSON,
+	ContentTypeXML:       formatXML,
+	"text/xml":           formatXML,
+	"text/csv":           formatCSV,
+	"application/csv":    formatCSV,
+	"application/yaml":   formatYAML,
+	"application/x-yaml": formatYAML,
+	"text/yaml":          formatYAML,
+	"text/x-yaml":        formatYAML,
+}
+
+// responseFormat returns the format of a response from its content type, recognizing structured
+// syntax suffixes such as +json and +xml.
+func responseFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if format, ok := responseFormats[mediaType]; ok {
+		return format
+	}
+	if _, suffix, found := strings.Cut(mediaType, "+"); found {
+		return responseFormats["application/"+suffix]
+	}
+	return ""
+}
+
+// processResponse converts the response body to JSON based on its content type.
+// JSON responses are compacted, XML, CSV and YAML responses are converted to JSON and other
+// responses are returned as is. The raw response option skips the conversion.
+// Returns whether the result is JSON, which is false for responses that could not be converted,
+// and an error only if a JSON response is invalid.
+func processResponse(body []byte, contentType string, options ToolOptions) (string, bool, error) {
+	if options.RawResponse {
+		return string(body), responseFormat(contentType) == formatJSON && json.Valid(body), nil
+	}
+	var converted []byte
+	var err error
+	switch responseFormat(contentType) {
+	case formatJSON:
+		text, err := processJsonResponse(string(body))
+		return text, err == nil, err
+	case formatXML:
+		converted, err = xmlToJSON(body)
+	case formatCSV:
+		converted, err = csvToJSON(body)
+	case formatYAML:
+		converted, err = yamlToJSON(body)
+	default:
+		return string(body), false, nil
+	}
+	if err != nil {
+		// The response is still useful as text when it can not be converted
+		logger.Warn("Failed to convert response to JSON, returning it as received", "contentType", contentType, "error", err)
+		return string(body), false, nil
+	}
+	return string(converted), true, nil
+}
+
+// xmlNode is an XML element with its attributes, text and child elements in document order.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*xmlNode
+}
+
+// xmlToJSON converts an XML document to a JSON object keyed by the root element name.
+// Attributes are prefixed with @, text next to attributes or child elements is held by #text and
+// repeated child elements are grouped into arrays. Namespace prefixes are dropped.
+func xmlToJSON(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *xmlNode
+	var stack []*xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				// Namespace declarations are not part of the data
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs = append(node.attrs, attr)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("XML document has no root element")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	writeJSONString(&buf, root.name)
+	buf.WriteByte(':')
+	writeXMLNode(&buf, root)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeXMLNode(buf *bytes.Buffer, node *xmlNode) {
+	text := strings.TrimSpace(node.text.String())
+	if len(node.attrs) == 0 && len(node.children) == 0 {
+		writeJSONString(buf, text)
+		return
+	}
+	buf.WriteByte('{')
+	first := true
+	writeKey := func(key string) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeJSONString(buf, key)
+		buf.WriteByte(':')
+	}
+	for _, attr := range node.attrs {
+		writeKey("@" + attr.Name.Local)
+		writeJSONString(buf, attr.Value)
+	}
+	// Group the children by name in the order the names first appear
+	var names []string
+	groups := make(map[string][]*xmlNode)
+	for _, child := range node.children {
+		if _, exists := groups[child.name]; !exists {
+			names = append(names, child.name)
+		}
+		groups[child.name] = append(groups[child.name], child)
+	}
+	for _, name := range names {
+		writeKey(name)
+		group := groups[name]
+		if len(group) == 1 {
+			writeXMLNode(buf, group[0])
+			continue
+		}
+		buf.WriteByte('[')
+		for i, child := range group {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeXMLNode(buf, child)
+		}
+		buf.WriteByte(']')
+	}
+	if text != "" {
+		writeKey("#text")
+		writeJSONString(buf, text)
+	}
+	buf.WriteByte('}')
+}
+
+// csvToJSON converts a CSV document with a header row to a JSON array with an object per record.
+// Repeated column names are suffixed with _2, _3 and so on so that keys are unique.
+func csvToJSON(data []byte) ([]byte, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []byte("[]"), nil
+	}
+	header := uniqueColumnNames(records[0])
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, record := range records[1:] {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for j, value := range record {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(&buf, header[j])
+			buf.WriteByte(':')
+			writeJSONString(&buf, value)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// uniqueColumnNames suffixes repeated column names, skipping suffixed names that are columns of
+// their own.
+func uniqueColumnNames(names []string) []string {
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = true
+	}
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, len(names))
+	for i, name := range names {
+		candidate := name
+		for count := 2; seen[candidate] || (candidate != name && taken[candidate]); count++ {
+			candidate = fmt.Sprintf("%s_%d", name, count)
+		}
+		seen[candidate] = true
+		unique[i] = candidate
+	}
+	return unique
+}
+
+// yamlToJSON converts the first document of a YAML stream to JSON, keeping the order of keys.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeYAMLNode(&buf, &node, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeYAMLNode(buf *bytes.Buffer, node *yaml.Node, depth int) error {
+	if depth > maxSchemaDepth {
+		return fmt.Errorf("YAML document is nested too deeply")
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeYAMLNode(buf, node.Content[0], depth+1)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteByte(':')
+			if err := writeYAMLNode(buf, node.Content[i+1], depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLNode(buf, child, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.AliasNode:
+		return writeYAMLNode(buf, node.Alias, depth+1)
+	case yaml.ScalarNode:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	default:
+		return fmt.Errorf("unsupported YAML node at line %d", node.Line)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoded, _ := json.Marshal(s)
+	buf.Write(encoded)
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import "testing"
+
+func TestProcessResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		options     ToolOptions
+		want        string
+		// notJSON is set for responses returned as text
+		notJSON bool
+		wantErr bool
+	}{
+		{
+			name:        "json",
+			body:        "{\n  \"id\": 1\n}",
+			contentType: "application/json; charset=utf-8",
+			want:        `{"id":1}`,
+		},
+		{
+			name:        "json array",
+			body:        "[ {\"b\": 1, \"a\": 2}, 3 ]",
+			contentType: ContentTypeJSON,
+			want:        `[{"b":1,"a":2},3]`,
+		},
+		{
+			name:        "json scalar with large number",
+			body:        " 12345678901234567890.000000000001 ",
+			contentType: ContentTypeJSON,
+			want:        "12345678901234567890.000000000001",
+		},
+		{
+			name:        "empty json",
+			body:        "",
+			contentType: ContentTypeJSON,
+			want:        "",
+		},
+		{
+			name:        "invalid json",
+			body:        "{",
+			contentType: ContentTypeJSON,
+			wantErr:     true,
+		},
+		{
+			name:        "xml",
+			body:        `<?xml version="1.0"?><ns:order xmlns:ns="urn:orders" id="7"><item>book</item><item>pen</item><note lang="en">gift</note><total/></ns:order>`,
+			contentType: "text/xml",
+			want:        `{"order":{"@id":"7","item":["book","pen"],"note":{"@lang":"en","#text":"gift"},"total":""}}`,
+		},
+		{
+			name:        "problem xml suffix",
+			body:        `<problem><title>Not found</title></problem>`,
+			contentType: "application/problem+xml",
+			want:        `{"problem":{"title":"Not found"}}`,
+		},
+		{
+			name:        "csv",
+			body:        "id,name\n1,Alice\n2,\"Smith, Bob\"\n",
+			contentType: "text/csv",
+			want:        `[{"id":"1","name":"Alice"},{"id":"2","name":"Smith, Bob"}]`,
+		},
+		{
+			name:        "csv with repeated column names",
+			body:        "id,tag,tag,tag_2\n1,a,b,c\n",
+			contentType: "text/csv",
+			want:        `[{"id":"1","tag":"a","tag_3":"b","tag_2":"c"}]`,
+		},
+		{
+			name:        "yaml",
+			body:        "name: svc\nreplicas: 2\nports:\n  - 80\n  - 443\nenabled: true\n",
+			contentType: "application/yaml",
+			want:        `{"name":"svc","replicas":2,"ports":[80,443],"enabled":true}`,
+		},
+		{
+			name:        "malformed xml is returned as is",
+			body:        "<order>",
+			contentType: ContentTypeXML,
+			want:        "<order>",
+			notJSON:     true,
+		},
+		{
+			name:        "raw response",
+			body:        "<order/>",
+			contentType: ContentTypeXML,
+			options:     ToolOptions{RawResponse: true},
+			want:        "<order/>",
+			notJSON:     true,
+		},
+		{
+			name:        "plain text",
+			body:        "ok",
+			contentType: "text/plain",
+			want:        "ok",
+			notJSON:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isJSON, err := processResponse([]byte(tt.body), tt.contentType, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("processResponse() = %v, want %v", got, tt.want)
+			}
+			if wantJSON := !tt.wantErr && !tt.notJSON; isJSON != wantJSON {
+				t.Errorf("processResponse() JSON = %v, want %v", isJSON, wantJSON)
+			}
+		})
+	}
+}
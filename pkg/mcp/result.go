@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NewCallToolResult creates the result of a tool call from the response of the underlying API.
+// Images and audio are returned as base64 encoded content, other binary responses as an embedded
+// blob resource and text responses as text content. JSON objects are also returned as structured
+// content. Truncated responses and paginated responses with more pages are followed by a notice.
+// Error responses are returned as tool errors.
+func NewCallToolResult(resp *APIResponse) *CallToolResult {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return NewBackendError(resp).Result()
+	}
+	result := &CallToolResult{}
+	mediaType := responseMediaType(resp.ContentType)
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		result.Content = []Content{{Type: "image", Data: base64.StdEncoding.EncodeToString(resp.Body), MimeType: mediaType}}
+	case strings.HasPrefix(mediaType, "audio/"):
+		result.Content = []Content{{Type: "audio", Data: base64.StdEncoding.EncodeToString(resp.Body), MimeType: mediaType}}
+	case isBinaryResponse(mediaType):
+		result.Content = []Content{{Type: "resource", Resource: &EmbeddedResource{
+			URI:      resp.URL,
+			MimeType: mediaType,
+			Blob:     base64.StdEncoding.EncodeToString(resp.Body),
+		}}}
+	default:
+		result.Content = []Content{{Type: "text", Text: resp.Text}}
+		if isJSONObject(resp.Text) {
+			result.StructuredContent = json.RawMessage(resp.Text)
+		}
+		if resp.Truncated {
+			result.Content = append(result.Content, Content{Type: "text", Text: truncationNotice(resp)})
+		}
+		if resp.HasMore {
+			result.Content = append(result.Content, Content{Type: "text", Text: nextPageNotice(resp)})
+		}
+	}
+	return result
+}
+
+// NewToolErrorResult creates the result of a tool call that failed before the underlying API
+// responded, so that the model can see the error.
+func NewToolErrorResult(err error) *CallToolResult {
+	return NewToolError(err).Result()
+}
+
+func formatValidationErrors(title string, errors []ValidationError) string {
+	var builder strings.Builder
+	builder.WriteString(title)
+	for _, e := range errors {
+		fmt.Fprintf(&builder, "\n- %s: %s", e.Path, e.Message)
+	}
+	return builder.String()
+}
+
+func responseMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// isBinaryMediaType reports whether responses of the media type are not text.
+func isBinaryMediaType(mediaType string) bool {
+	if mediaType == "" || strings.HasPrefix(mediaType, "text/") || responseFormat(mediaType) != "" {
+		return false
+	}
+	switch mediaType {
+	case "application/javascript", "application/x-www-form-urlencoded", "application/graphql":
+		return false
+	}
+	return strings.HasPrefix(mediaType, "application/") || strings.HasPrefix(mediaType, "video/") ||
+		strings.HasPrefix(mediaType, "font/") || strings.HasPrefix(mediaType, "model/")
+}
+
+// isBinaryResponse reports whether responses of the media type are returned base64 encoded.
+func isBinaryResponse(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "image/") || strings.HasPrefix(mediaType, "audio/") || isBinaryMediaType(mediaType)
+}
+
+// binaryResponseText returns the text form of a binary response, a JSON object holding the media
+// type and the base64 encoded data.
+func binaryResponseText(mediaType string, body []byte) string {
+	text, _ := json.Marshal(struct {
+		MimeType string `json:"mimeType"`
+		Data     string `json:"data"`
+	}{mediaType, base64.StdEncoding.EncodeToString(body)})
+	return string(text)
+}
+
+func isJSONObject(text string) bool {
+	trimmed := strings.TrimSpace(text)
+	return strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed))
+}
+
+// nextPageNotice tells the model that more pages are available and how to continue.
+func nextPageNotice(resp *APIResponse) string {
+	if resp.NextPage == "" {
+		return "More results are available than the pages returned."
+	}
+	if resp.NextPageArgument == "" {
+		return fmt.Sprintf("More results are available than the pages returned, the next page is %s.", resp.NextPage)
+	}
+	return fmt.Sprintf("More results are available. Call the tool again with the %s argument set to %s to continue.", resp.NextPageArgument, resp.NextPage)
+}
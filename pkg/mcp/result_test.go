@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewCallToolResult(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *APIResponse
+		want string
+	}{
+		{
+			name: "json object",
+			resp: &APIResponse{StatusCode: 200, ContentType: ContentTypeJSON, Text: `{"id":1}`},
+			want: `{"content":[{"type":"text","text":"{\"id\":1}"}],"structuredContent":{"id":1}}`,
+		},
+		{
+			name: "json array",
+			resp: &APIResponse{StatusCode: 200, ContentType: ContentTypeJSON, Text: `[1,2]`},
+			want: `{"content":[{"type":"text","text":"[1,2]"}]}`,
+		},
+		{
+			name: "error status",
+			resp: &APIResponse{StatusCode: 404, ContentType: "text/plain", Text: "not found"},
+			want: `{"content":[{"type":"text","text":"The underlying API returned 404 Not Found: not found"}],"structuredContent":{"error":"The underlying API returned 404 Not Found: not found","type":"backend_client_error","backend_status":404,"details":"not found"},"isError":true}`,
+		},
+		{
+			name: "json error status",
+			resp: &APIResponse{StatusCode: 503, ContentType: ContentTypeJSON, Text: `{"error":{"code":"busy","message":"Try again later"}}`},
+			want: `{"content":[{"type":"text","text":"The underlying API returned 503 Service Unavailable: Try again later"}],"structuredContent":{"error":"The underlying API returned 503 Service Unavailable: Try again later","type":"backend_server_error","backend_status":503,"details":{"error":{"code":"busy","message":"Try again later"}}},"isError":true}`,
+		},
+		{
+			name: "image",
+			resp: &APIResponse{StatusCode: 200, ContentType: "image/png", Body: []byte{0x89, 'P', 'N', 'G'}},
+			want: `{"content":[{"type":"image","data":"iVBORw==","mimeType":"image/png"}]}`,
+		},
+		{
+			name: "audio",
+			resp: &APIResponse{StatusCode: 200, ContentType: "audio/mpeg", Body: []byte("ID3")},
+			want: `{"content":[{"type":"audio","data":"SUQz","mimeType":"audio/mpeg"}]}`,
+		},
+		{
+			name: "binary resource",
+			resp: &APIResponse{StatusCode: 200, ContentType: "application/pdf", URL: "http://localhost/doc", Body: []byte("%PDF")},
+			want: `{"content":[{"type":"resource","resource":{"uri":"http://localhost/doc","mimeType":"application/pdf","blob":"JVBERg=="}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewCallToolResult(tt.resp))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("NewCallToolResult() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToolErrorResult(t *testing.T) {
+	validationErr := &ArgumentsValidationError{Errors: []ValidationError{{Path: "arguments.id", Message: "is required"}}}
+	if got := NewToolErrorResult(validationErr); !got.IsError || got.Content[0].Text != "Invalid arguments:\n- arguments.id: is required" {
+		t.Errorf("NewToolErrorResult() = %+v", got)
+	}
+	if got := NewToolErrorResult(errors.New("connection refused")); !got.IsError || got.Content[0].Text != "Failed to call underlying API: connection refused" {
+		t.Errorf("NewToolErrorResult() = %+v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"mcp-server/pkg/service"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// retryPolicy decides which attempts of a call to the underlying API are retried.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	statusCodes    []int
+	// nonIdempotent allows retrying POST and PATCH requests
+	nonIdempotent bool
+}
+
+// newRetryPolicy returns the retry policy of the tool, which overrides the configured policy.
+func newRetryPolicy(options ToolOptions) retryPolicy {
+	policy := retryPolicy{
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff * time.Millisecond,
+		maxBackoff:     defaultMaxBackoff * time.Millisecond,
+		statusCodes:    defaultRetryStatusCodes,
+	}
+	if config := service.GetConfig(); config != nil {
+		retry := config.Http.Retry
+		policy.maxAttempts = retry.MaxAttempts
+		policy.initialBackoff = time.Duration(retry.InitialBackoff) * time.Millisecond
+		policy.maxBackoff = time.Duration(retry.MaxBackoff) * time.Millisecond
+		policy.statusCodes = retry.StatusCodes
+	}
+	if retry := options.Retry; retry != nil {
+		if retry.MaxAttempts > 0 {
+			policy.maxAttempts = retry.MaxAttempts
+		}
+		if retry.InitialBackoff > 0 {
+			policy.initialBackoff = time.Duration(retry.InitialBackoff) * time.Millisecond
+		}
+		if retry.MaxBackoff > 0 {
+			policy.maxBackoff = time.Duration(retry.MaxBackoff) * time.Millisecond
+		}
+		if retry.StatusCodes != nil {
+			policy.statusCodes = retry.StatusCodes
+		}
+		policy.nonIdempotent = retry.NonIdempotent
+	}
+	return policy
+}
+
+// allows reports whether requests with the method may be sent more than once.
+func (policy retryPolicy) allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost, http.MethodPatch:
+		return policy.nonIdempotent
+	}
+	return false
+}
+
+// backoff returns the delay before a retry, growing exponentially with full jitter.
+func (policy retryPolicy) backoff(retry int) time.Duration {
+	delay := policy.maxBackoff
+	if shift := retry - 1; shift < 32 {
+		delay = min(policy.initialBackoff<<shift, policy.maxBackoff)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay) + 1
+}
+
+// doRequest sends a request to the underlying API following the retry policy of the tool.
+// Responses with a retryable status code and failures to reach the API are retried when the
+// method allows it, after the Retry-After delay of the response or an exponential backoff.
+// The body of the request is replayed for each attempt.
+func doRequest(ctx context.Context, payload *MCPRequest, httpRequest *TransformedRequest) (*http.Response, error) {
+	httpClient := InitHttpClient()
+	policy := newRetryPolicy(payload.Options)
+	retries := policy.allows(httpRequest.Method)
+	for attempt := 1; ; attempt++ {
+		if httpRequest.Body != nil {
+			if _, err := httpRequest.Body.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		request, err := httpClient.GenerateRequest(ctx, httpRequest)
+		if err != nil {
+			logger.ErrorContext(ctx, "Failed to generate request", "error", err)
+			return nil, err
+		}
+		resp, err := httpClient.DoRequest(request, payload.Options)
+		if !retries || attempt >= policy.maxAttempts || !policy.retryable(ctx, resp, err) {
+			if err != nil {
+				logger.ErrorContext(ctx, "Failed to send request", "error", err, "attempts", attempt)
+			}
+			return resp, err
+		}
+		delay := policy.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			logger.WarnContext(ctx, "Not retrying request past the timeout of the call", "attempt", attempt, "delay", delay)
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so that the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		logger.WarnContext(ctx, "Retrying request", "attempt", attempt, "delay", delay, "status", statusOf(resp), "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying. Failed attempts are
+// retried, including attempts that timed out connecting or waiting for the response headers, but
+// not once the call is cancelled or has timed out, or the circuit breaker of the host is open.
+func (policy retryPolicy) retryable(ctx context.Context, resp *http.Response, err error) bool {
+	var unavailableErr *BackendUnavailableError
+	if ctx.Err() != nil || errors.As(err, &unavailableErr) {
+		return false
+	}
+	return err != nil || slices.Contains(policy.statusCodes, resp.StatusCode)
+}
+
+// parseRetryAfter parses a Retry-After header of delay seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
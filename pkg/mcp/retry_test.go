@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCallAPIRetry(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		attempts[r.URL.Path]++
+		attempt := attempts[r.URL.Path]
+		mu.Unlock()
+		if r.Method == http.MethodPost && string(body) != `{"id":7}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.URL.Path == "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+		case r.URL.Path == "/down" || attempt < 3:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set(ContentType, ContentTypeJSON)
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer backend.Close()
+
+	retry := &RetryOptions{InitialBackoff: 1, MaxBackoff: 5}
+	tests := []struct {
+		name         string
+		target       string
+		verb         string
+		retry        *RetryOptions
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "get is retried", target: "/get", verb: http.MethodGet, retry: retry, wantStatus: http.StatusOK, wantAttempts: 3},
+		{name: "post is not retried", target: "/post", verb: http.MethodPost, retry: retry, wantStatus: http.StatusServiceUnavailable, wantAttempts: 1},
+		{name: "post is retried when allowed", target: "/post-allowed", verb: http.MethodPost, retry: &RetryOptions{InitialBackoff: 1, NonIdempotent: true}, wantStatus: http.StatusOK, wantAttempts: 3},
+		{name: "attempts are limited", target: "/down", verb: http.MethodDelete, retry: &RetryOptions{MaxAttempts: 2, InitialBackoff: 1}, wantStatus: http.StatusServiceUnavailable, wantAttempts: 2},
+		{name: "status code is not retryable", target: "/teapot", verb: http.MethodGet, retry: retry, wantStatus: http.StatusTeapot, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := newBackendRequest(backend.URL, ToolOptions{Retry: tt.retry})
+			request.Backend.Target = tt.target
+			request.Backend.Verb = tt.verb
+			if tt.verb == http.MethodPost {
+				request.Schema = `{"type":"object","properties":{"requestBody":{"type":"object"}}}`
+				request.Arguments = `{"requestBody":{"id":7}}`
+			}
+			resp, err := CallAPI(context.Background(), request)
+			if err != nil {
+				t.Fatalf("CallAPI() error = %v", err)
+			}
+			mu.Lock()
+			got := attempts[tt.target]
+			mu.Unlock()
+			if resp.StatusCode != tt.wantStatus || got != tt.wantAttempts {
+				t.Errorf("CallAPI() status = %d after %d attempts, want %d after %d", resp.StatusCode, got, tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "3", want: 3 * time.Second, wantOk: true},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOk: true},
+		{value: "", wantOk: false},
+		{value: "-1", wantOk: false},
+		{value: "soon", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseRetryAfter() = %v %v, want %v %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCallAPIRetryTimeout(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		attempt := attempts
+		mu.Unlock()
+		if attempt == 1 {
+			// The first attempt times out waiting for the response headers
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set(ContentType, ContentTypeJSON)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	request := newBackendRequest(backend.URL, ToolOptions{ResponseHeaderTimeout: 1, Retry: &RetryOptions{InitialBackoff: 1}})
+	resp, err := CallAPI(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallAPI() error = %v", err)
+	}
+	if resp.Text != `{"ok":true}` || attempts != 2 {
+		t.Errorf("CallAPI() = %s after %d attempts", resp.Text, attempts)
+	}
+}
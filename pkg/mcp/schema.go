@@ -7,6 +7,9 @@ import (
 )
 
 func processSchema(schema string) (*SchemaMapping, error) {
+	if schema == "" {
+		return &SchemaMapping{ContentType: ContentTypeJSON}, nil
+	}
 	var inputSchema MCPInputSchema
 	err := json.Unmarshal([]byte(schema), &inputSchema)
 	if err != nil {
@@ -25,50 +28,117 @@ func processSchema(schema string) (*SchemaMapping, error) {
 
 }
 
+// processInputProperties maps the input properties to the parameters of the request.
+// The location of a property is taken from the x-mcp-in extension when present, falling back to
+// the path_, query_, header_ and cookie_ name prefixes.
 func processInputProperties(properties map[string]any, requiredParams []string) *SchemaMapping {
-	var pathParameters []string
+	var pathParameters []Param
 	var queryParameters []Param
 	var headerParameters []Param
+	var cookieParameters []Param
 	var requestBody bool
-	for k := range properties {
-		name := k
+	var bodyProperty string
+	for _, name := range sortedKeys(properties) {
+		required := slices.Contains(requiredParams, name)
+
+		if location, paramName, found := explicitLocation(name, properties[name]); found {
+			param := Param{
+				Name:     paramName,
+				Required: required,
+				Property: name,
+			}
+			param.Style, param.Explode = paramStyle(location, properties[name])
+			switch location {
+			case LocationQuery:
+				queryParameters = append(queryParameters, param)
+			case LocationHeader:
+				headerParameters = append(headerParameters, param)
+			case LocationCookie:
+				cookieParameters = append(cookieParameters, param)
+			case LocationPath:
+				param.Required = true
+				pathParameters = append(pathParameters, param)
+			case LocationBody:
+				requestBody = true
+				bodyProperty = name
+			default:
+				logger.Warn("Unsupported parameter location", "name", name, "location", location)
+			}
+			continue
+		}
 
 		if strings.HasPrefix(name, "query_") {
 			refinedName := strings.TrimPrefix(name, "query_")
 			param := Param{
 				Name:     refinedName,
-				Required: false,
-			}
-			if slices.Contains(requiredParams, name) {
-				param.Required = true
+				Required: required,
+				Property: name,
+				Prefixed: true,
 			}
+			param.Style, param.Explode = paramStyle(LocationQuery, properties[name])
 			queryParameters = append(queryParameters, param)
 		} else if strings.HasPrefix(name, "header_") {
 			refinedName := strings.TrimPrefix(name, "header_")
 			param := Param{
 				Name:     refinedName,
-				Required: false,
-			}
-			if slices.Contains(requiredParams, name) {
-				param.Required = true
+				Required: required,
+				Property: name,
+				Prefixed: true,
 			}
+			param.Style, param.Explode = paramStyle(LocationHeader, properties[name])
 			headerParameters = append(headerParameters, param)
+		} else if strings.HasPrefix(name, "cookie_") {
+			refinedName := strings.TrimPrefix(name, "cookie_")
+			param := Param{
+				Name:     refinedName,
+				Required: required,
+				Property: name,
+				Prefixed: true,
+			}
+			param.Style, param.Explode = paramStyle(LocationCookie, properties[name])
+			cookieParameters = append(cookieParameters, param)
 		} else if strings.HasPrefix(name, "path_") {
 			refinedName := strings.TrimPrefix(name, "path_")
-			pathParameters = append(pathParameters, refinedName)
+			param := Param{
+				Name:     refinedName,
+				Required: true,
+				Property: name,
+				Prefixed: true,
+			}
+			param.Style, param.Explode = paramStyle(LocationPath, properties[name])
+			pathParameters = append(pathParameters, param)
 		} else if name == "requestBody" {
 			requestBody = true
+			bodyProperty = name
 		} else {
 			logger.Warn("Unknown property prefix", "name", name)
 		}
-
 	}
 
 	schemaMapping := &SchemaMapping{
 		PathParameters:   pathParameters,
 		QueryParameters:  queryParameters,
 		HeaderParameters: headerParameters,
+		CookieParameters: cookieParameters,
 		HasBody:          requestBody,
+		BodyProperty:     bodyProperty,
 	}
 	return schemaMapping
 }
+
+// explicitLocation returns the location declared by the x-mcp-in extension of a property and
+// the parameter name, which is the x-mcp-name extension if present or the property name otherwise.
+func explicitLocation(name string, property any) (string, string, bool) {
+	propertySchema, ok := property.(map[string]any)
+	if !ok {
+		return "", "", false
+	}
+	location, ok := propertySchema[ExtensionIn].(string)
+	if !ok || location == "" {
+		return "", "", false
+	}
+	if paramName, ok := propertySchema[ExtensionName].(string); ok && paramName != "" {
+		name = paramName
+	}
+	return strings.ToLower(location), name, true
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestProcessSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   *SchemaMapping
+	}{
+		{
+			name: "prefixed properties",
+			schema: `{"type":"object","required":["query_q","header_X-Tenant"],"properties":{
+				"path_id":{"type":"string"},
+				"query_q":{"type":"string"},
+				"header_X-Tenant":{"type":"string"},
+				"cookie_session":{"type":"string"},
+				"requestBody":{"type":"object"}
+			}}`,
+			want: &SchemaMapping{
+				PathParameters:   []Param{{Name: "id", Required: true, Property: "path_id", Prefixed: true}},
+				QueryParameters:  []Param{{Name: "q", Required: true, Property: "query_q", Prefixed: true}},
+				HeaderParameters: []Param{{Name: "X-Tenant", Required: true, Property: "header_X-Tenant", Prefixed: true}},
+				CookieParameters: []Param{{Name: "session", Property: "cookie_session", Prefixed: true}},
+				HasBody:          true,
+				BodyProperty:     "requestBody",
+				ContentType:      ContentTypeJSON,
+			},
+		},
+		{
+			name: "explicit locations",
+			schema: `{"type":"object","required":["tenant"],"contentType":"application/xml","properties":{
+				"userId":{"type":"string","x-mcp-in":"path"},
+				"userIdFilter":{"type":"string","x-mcp-in":"query","x-mcp-name":"userId"},
+				"tenant":{"type":"string","x-mcp-in":"header","x-mcp-name":"X-Tenant"},
+				"lang":{"type":"string","x-mcp-in":"cookie"},
+				"order":{"type":"object","x-mcp-in":"body"}
+			}}`,
+			want: &SchemaMapping{
+				PathParameters:   []Param{{Name: "userId", Required: true, Property: "userId"}},
+				QueryParameters:  []Param{{Name: "userId", Required: false, Property: "userIdFilter"}},
+				HeaderParameters: []Param{{Name: "X-Tenant", Required: true, Property: "tenant"}},
+				CookieParameters: []Param{{Name: "lang", Property: "lang"}},
+				HasBody:          true,
+				BodyProperty:     "order",
+				ContentType:      ContentTypeXML,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processSchema(tt.schema)
+			if err != nil {
+				t.Fatalf("processSchema() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("processSchema() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestLookupParam(t *testing.T) {
+	args := map[string]any{"userId": "path-value", "userIdFilter": "query-value", "query_q": "prefixed", "id": "unprefixed"}
+	tests := []struct {
+		name  string
+		param Param
+		want  any
+	}{
+		{"explicit property", Param{Name: "userId", Property: "userIdFilter"}, "query-value"},
+		{"explicit property does not fall back to the parameter name", Param{Name: "userId", Property: "missing"}, nil},
+		{"prefixed property", Param{Name: "q", Property: "query_q", Prefixed: true}, "prefixed"},
+		{"prefixed property sent without prefix", Param{Name: "id", Property: "path_id", Prefixed: true}, "unprefixed"},
+		{"parameter name only", Param{Name: "id"}, "unprefixed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookupParam(args, tt.param); got != tt.want {
+				t.Errorf("lookupParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
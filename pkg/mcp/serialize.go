@@ -0,0 +1,300 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Parameter serialization styles as defined by OpenAPI 3
+const (
+	StyleForm           = "form"
+	StyleSpaceDelimited = "spaceDelimited"
+	StylePipeDelimited  = "pipeDelimited"
+	StyleDeepObject     = "deepObject"
+	StyleSimple         = "simple"
+	StyleLabel          = "label"
+	StyleMatrix         = "matrix"
+)
+
+// styles allowed for each parameter location
+var locationStyles = map[string][]string{
+	LocationQuery:  {StyleForm, StyleSpaceDelimited, StylePipeDelimited, StyleDeepObject},
+	LocationPath:   {StyleSimple, StyleLabel, StyleMatrix},
+	LocationHeader: {StyleSimple},
+	LocationCookie: {StyleForm},
+}
+
+// paramStyle returns the serialization style and explode flag declared by the property schema.
+// Empty values mean the OpenAPI defaults of the parameter location apply.
+func paramStyle(location string, property any) (string, *bool) {
+	propertySchema, ok := property.(map[string]any)
+	if !ok {
+		return "", nil
+	}
+	style, _ := propertySchema["style"].(string)
+	if style != "" && !slices.Contains(locationStyles[location], style) {
+		logger.Warn("Unsupported parameter style, using the default", "style", style, "location", location)
+		style = ""
+	}
+	var explode *bool
+	if declared, ok := propertySchema["explode"].(bool); ok {
+		explode = &declared
+	}
+	return style, explode
+}
+
+// serializeQueryParam serializes a query parameter following its style and explode flag.
+// Returns the URL encoded name=value pairs to add to the query string.
+func serializeQueryParam(param Param, value any) []string {
+	name := escapeQueryComponent(param.Name)
+	style := param.Style
+	if style == "" {
+		style = StyleForm
+	}
+	// Query parameters explode by default for the form and deepObject styles
+	explode := style == StyleForm || style == StyleDeepObject
+	if param.Explode != nil {
+		explode = *param.Explode
+	}
+
+	switch val := value.(type) {
+	case []any:
+		values := escapeValues(val, escapeQueryComponent)
+		if explode && style != StyleDeepObject {
+			pairs := make([]string, 0, len(values))
+			for _, v := range values {
+				pairs = append(pairs, name+"="+v)
+			}
+			return pairs
+		}
+		return []string{name + "=" + strings.Join(values, styleDelimiter(style))}
+	case map[string]any:
+		if style == StyleDeepObject {
+			return deepObjectPairs(name, val)
+		}
+		if explode && style == StyleForm {
+			pairs := make([]string, 0, len(val))
+			for _, k := range sortedKeys(val) {
+				pairs = append(pairs, escapeQueryComponent(k)+"="+escapeQueryComponent(formatParamValue(val[k])))
+			}
+			return pairs
+		}
+		return []string{name + "=" + strings.Join(escapeKeyValues(val, escapeQueryComponent), styleDelimiter(style))}
+	default:
+		return []string{name + "=" + escapeQueryComponent(formatParamValue(value))}
+	}
+}
+
+// serializePathParam serializes a path parameter following its style and explode flag.
+// Returns the URL encoded value to replace the path template with.
+func serializePathParam(param Param, value any) string {
+	explode := param.Explode != nil && *param.Explode
+	name := url.PathEscape(param.Name)
+
+	switch param.Style {
+	case StyleLabel:
+		switch val := value.(type) {
+		case []any:
+			return "." + strings.Join(escapeValues(val, url.PathEscape), labelDelimiter(explode))
+		case map[string]any:
+			if explode {
+				return "." + strings.Join(escapeKeyPairs(val, url.PathEscape), ".")
+			}
+			return "." + strings.Join(escapeKeyValues(val, url.PathEscape), ",")
+		default:
+			return "." + url.PathEscape(formatParamValue(value))
+		}
+	case StyleMatrix:
+		switch val := value.(type) {
+		case []any:
+			if explode {
+				var builder strings.Builder
+				for _, v := range escapeValues(val, url.PathEscape) {
+					builder.WriteString(";" + name + "=" + v)
+				}
+				return builder.String()
+			}
+			return ";" + name + "=" + strings.Join(escapeValues(val, url.PathEscape), ",")
+		case map[string]any:
+			if explode {
+				return ";" + strings.Join(escapeKeyPairs(val, url.PathEscape), ";")
+			}
+			return ";" + name + "=" + strings.Join(escapeKeyValues(val, url.PathEscape), ",")
+		default:
+			return ";" + name + "=" + url.PathEscape(formatParamValue(value))
+		}
+	default:
+		return serializeSimple(value, explode, url.PathEscape)
+	}
+}
+
+// serializeHeaderParam serializes a header parameter using the simple style.
+func serializeHeaderParam(param Param, value any) string {
+	explode := param.Explode != nil && *param.Explode
+	return serializeSimple(value, explode, func(s string) string { return s })
+}
+
+// serializeCookieParam serializes a cookie parameter using the form style.
+// Returns the name=value pairs to add to the Cookie header, with values URL encoded so that
+// they only contain valid cookie characters, or an error if an exploded object key is not a
+// valid cookie name.
+func serializeCookieParam(param Param, value any) ([]string, error) {
+	// Cookies explode by default like other form style parameters
+	explode := param.Explode == nil || *param.Explode
+	switch val := value.(type) {
+	case []any:
+		if explode {
+			pairs := make([]string, 0, len(val))
+			for _, v := range escapeValues(val, escapeQueryComponent) {
+				pairs = append(pairs, param.Name+"="+v)
+			}
+			return pairs, nil
+		}
+		return []string{param.Name + "=" + strings.Join(escapeValues(val, escapeQueryComponent), ",")}, nil
+	case map[string]any:
+		if !explode {
+			return []string{param.Name + "=" + strings.Join(escapeKeyValues(val, escapeQueryComponent), ",")}, nil
+		}
+		pairs := make([]string, 0, len(val))
+		for _, k := range sortedKeys(val) {
+			// Exploded keys are cookie names, which cannot be escaped
+			if !isCookieName(k) {
+				return nil, fmt.Errorf("%w: invalid cookie name %q in cookie parameter %s", ErrInvalidArguments, k, param.Name)
+			}
+			pairs = append(pairs, k+"="+escapeQueryComponent(formatParamValue(val[k])))
+		}
+		return pairs, nil
+	default:
+		return []string{param.Name + "=" + escapeQueryComponent(formatParamValue(value))}, nil
+	}
+}
+
+// isCookieName reports whether the name is a valid cookie name, which must be an HTTP token.
+func isCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func serializeSimple(value any, explode bool, escape func(string) string) string {
+	switch val := value.(type) {
+	case []any:
+		values := make([]string, 0, len(val))
+		for _, v := range val {
+			values = append(values, escape(formatParamValue(v)))
+		}
+		return strings.Join(values, ",")
+	case map[string]any:
+		parts := make([]string, 0, len(val)*2)
+		for _, k := range sortedKeys(val) {
+			if explode {
+				parts = append(parts, escape(k)+"="+escape(formatParamValue(val[k])))
+			} else {
+				parts = append(parts, escape(k), escape(formatParamValue(val[k])))
+			}
+		}
+		return strings.Join(parts, ",")
+	default:
+		return escape(formatParamValue(value))
+	}
+}
+
+// deepObjectPairs serializes an object as name[key]=value pairs, nesting brackets for nested objects.
+func deepObjectPairs(name string, value map[string]any) []string {
+	var pairs []string
+	for _, k := range sortedKeys(value) {
+		key := name + "[" + escapeQueryComponent(k) + "]"
+		switch v := value[k].(type) {
+		case map[string]any:
+			pairs = append(pairs, deepObjectPairs(key, v)...)
+		case []any:
+			for _, item := range escapeValues(v, escapeQueryComponent) {
+				pairs = append(pairs, key+"="+item)
+			}
+		default:
+			pairs = append(pairs, key+"="+escapeQueryComponent(formatParamValue(v)))
+		}
+	}
+	return pairs
+}
+
+func styleDelimiter(style string) string {
+	switch style {
+	case StyleSpaceDelimited:
+		return "%20"
+	case StylePipeDelimited:
+		return "|"
+	default:
+		return ","
+	}
+}
+
+func labelDelimiter(explode bool) string {
+	if explode {
+		return "."
+	}
+	return ","
+}
+
+// escapeQueryComponent escapes a query name or value, leaving only the RFC 3986 unreserved
+// characters so that &, =, + and # in values cannot change the query string.
+func escapeQueryComponent(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func escapeValues(values []any, escape func(string) string) []string {
+	escaped := make([]string, 0, len(values))
+	for _, v := range values {
+		escaped = append(escaped, escape(formatParamValue(v)))
+	}
+	return escaped
+}
+
+// escapeKeyValues flattens an object into alternating escaped keys and values.
+func escapeKeyValues(value map[string]any, escape func(string) string) []string {
+	escaped := make([]string, 0, len(value)*2)
+	for _, k := range sortedKeys(value) {
+		escaped = append(escaped, escape(k), escape(formatParamValue(value[k])))
+	}
+	return escaped
+}
+
+// escapeKeyPairs flattens an object into escaped key=value pairs.
+func escapeKeyPairs(value map[string]any, escape func(string) string) []string {
+	escaped := make([]string, 0, len(value))
+	for _, k := range sortedKeys(value) {
+		escaped = append(escaped, escape(k)+"="+escape(formatParamValue(value[k])))
+	}
+	return escaped
+}
+
+// formatParamValue formats a primitive argument value as a parameter string.
+// Nested arrays and objects are formatted as JSON.
+func formatParamValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any, map[string]any:
+		formatted, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(formatted)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
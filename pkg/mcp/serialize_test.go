@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+var (
+	testArray  = []any{"blue", "black", "brown"}
+	testObject = map[string]any{"R": float64(100), "G": float64(200), "B": float64(150)}
+)
+
+func TestSerializeQueryParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		param Param
+		value any
+		want  string
+	}{
+		{"primitive", Param{Name: "id"}, float64(5), "id=5"},
+		{"large number", Param{Name: "id"}, float64(1000000), "id=1000000"},
+		{"form explode array", Param{Name: "color"}, testArray, "color=blue&color=black&color=brown"},
+		{"form array", Param{Name: "color", Explode: boolPtr(false)}, testArray, "color=blue,black,brown"},
+		{"form explode object", Param{Name: "color"}, testObject, "B=150&G=200&R=100"},
+		{"form object", Param{Name: "color", Explode: boolPtr(false)}, testObject, "color=B,150,G,200,R,100"},
+		{"space delimited array", Param{Name: "color", Style: StyleSpaceDelimited}, testArray, "color=blue%20black%20brown"},
+		{"pipe delimited array", Param{Name: "color", Style: StylePipeDelimited}, testArray, "color=blue|black|brown"},
+		{"deep object", Param{Name: "color", Style: StyleDeepObject}, testObject, "color[B]=150&color[G]=200&color[R]=100"},
+		{"nested deep object", Param{Name: "filter", Style: StyleDeepObject}, map[string]any{"status": map[string]any{"in": []any{"a", "b"}}}, "filter[status][in]=a&filter[status][in]=b"},
+		{"escaped values", Param{Name: "q", Explode: boolPtr(false)}, []any{"a b", "c,d"}, "q=a%20b,c%2Cd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(serializeQueryParam(tt.param, tt.value), "&")
+			if got != tt.want {
+				t.Errorf("serializeQueryParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializePathParam(t *testing.T) {
+	tests := []struct {
+		name  string
+		param Param
+		value any
+		want  string
+	}{
+		{"simple primitive", Param{Name: "color"}, "blue", "blue"},
+		{"simple array", Param{Name: "color"}, testArray, "blue,black,brown"},
+		{"simple object", Param{Name: "color"}, testObject, "B,150,G,200,R,100"},
+		{"simple explode object", Param{Name: "color", Explode: boolPtr(true)}, testObject, "B=150,G=200,R=100"},
+		{"label primitive", Param{Name: "color", Style: StyleLabel}, "blue", ".blue"},
+		{"label array", Param{Name: "color", Style: StyleLabel}, testArray, ".blue,black,brown"},
+		{"label explode array", Param{Name: "color", Style: StyleLabel, Explode: boolPtr(true)}, testArray, ".blue.black.brown"},
+		{"label explode object", Param{Name: "color", Style: StyleLabel, Explode: boolPtr(true)}, testObject, ".B=150.G=200.R=100"},
+		{"matrix primitive", Param{Name: "color", Style: StyleMatrix}, "blue", ";color=blue"},
+		{"matrix array", Param{Name: "color", Style: StyleMatrix}, testArray, ";color=blue,black,brown"},
+		{"matrix explode array", Param{Name: "color", Style: StyleMatrix, Explode: boolPtr(true)}, testArray, ";color=blue;color=black;color=brown"},
+		{"matrix object", Param{Name: "color", Style: StyleMatrix}, testObject, ";color=B,150,G,200,R,100"},
+		{"matrix explode object", Param{Name: "color", Style: StyleMatrix, Explode: boolPtr(true)}, testObject, ";B=150;G=200;R=100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serializePathParam(tt.param, tt.value); got != tt.want {
+				t.Errorf("serializePathParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeHeaderParam(t *testing.T) {
+	if got := serializeHeaderParam(Param{Name: "X-Colors"}, testArray); got != "blue,black,brown" {
+		t.Errorf("serializeHeaderParam() = %v, want blue,black,brown", got)
+	}
+	if got := serializeHeaderParam(Param{Name: "X-Color", Explode: boolPtr(true)}, testObject); got != "B=150,G=200,R=100" {
+		t.Errorf("serializeHeaderParam() = %v, want B=150,G=200,R=100", got)
+	}
+}
+
+func TestSerializeCookieParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   Param
+		value   any
+		want    []string
+		wantErr bool
+	}{
+		{"primitive", Param{Name: "id"}, "a,b", []string{"id=a%2Cb"}, false},
+		{"escaped value", Param{Name: "id"}, "a b;c=d+e", []string{"id=a%20b%3Bc%3Dd%2Be"}, false},
+		{"array", Param{Name: "color", Explode: boolPtr(false)}, testArray, []string{"color=blue,black,brown"}, false},
+		{"array explode", Param{Name: "color"}, testArray, []string{"color=blue", "color=black", "color=brown"}, false},
+		{"object", Param{Name: "color", Explode: boolPtr(false)}, testObject, []string{"color=B,150,G,200,R,100"}, false},
+		{"object explode", Param{Name: "color"}, testObject, []string{"B=150", "G=200", "R=100"}, false},
+		{"object explode with invalid cookie name", Param{Name: "color"}, map[string]any{"a=b; admin": "true"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := serializeCookieParam(tt.param, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serializeCookieParam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serializeCookieParam() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
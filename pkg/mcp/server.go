@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mcp-server/pkg/service"
+	"slices"
+	"strings"
+)
+
+// Server handles MCP JSON-RPC 2.0 messages and dispatches tool calls to the underlying APIs.
+type Server struct {
+	info     Implementation
+	registry *ToolRegistry
+}
+
+func NewServer(name string, version string, registry *ToolRegistry) *Server {
+	if registry == nil {
+		registry = NewToolRegistry()
+	}
+	return &Server{
+		info:     Implementation{Name: name, Version: version},
+		registry: registry,
+	}
+}
+
+// Registry returns the registry of tools served by the server.
+func (s *Server) Registry() *ToolRegistry {
+	return s.registry
+}
+
+// HandleMessage processes a single JSON-RPC message.
+// Returns the response to be sent to the client, or nil if the message is a notification.
+func (s *Server) HandleMessage(ctx context.Context, message []byte) *JSONRPCResponse {
+	var request JSONRPCRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		logger.ErrorContext(ctx, "Failed to parse JSON-RPC message", "error", err)
+		return newErrorResponse(nil, ParseError, "Parse error", err.Error())
+	}
+	if request.JSONRPC != JSONRPCVersion || request.Method == "" {
+		return newErrorResponse(request.ID, InvalidRequest, "Invalid request", nil)
+	}
+	if request.ID == nil {
+		s.handleNotification(ctx, &request)
+		return nil
+	}
+
+	var result any
+	var rpcErr *JSONRPCError
+	switch request.Method {
+	case MethodInitialize:
+		result, rpcErr = s.initialize(ctx, request.Params)
+	case MethodPing:
+		result = struct{}{}
+	case MethodToolsList:
+		result, rpcErr = s.listTools(ctx, request.Params)
+	case MethodToolsCall:
+		result, rpcErr = s.callTool(ctx, request.Params)
+	default:
+		rpcErr = &JSONRPCError{Code: MethodNotFound, Message: fmt.Sprintf("Method not found: %s", request.Method)}
+	}
+	if rpcErr != nil {
+		return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: request.ID, Error: rpcErr}
+	}
+	return &JSONRPCResponse{JSONRPC: JSONRPCVersion, ID: request.ID, Result: result}
+}
+
+func (s *Server) handleNotification(ctx context.Context, request *JSONRPCRequest) {
+	switch request.Method {
+	case MethodNotificationInitialized:
+		logger.DebugContext(ctx, "Client initialized")
+	default:
+		logger.DebugContext(ctx, "Ignoring notification", "method", request.Method)
+	}
+}
+
+func (s *Server) initialize(ctx context.Context, rawParams json.RawMessage) (*InitializeResult, *JSONRPCError) {
+	var params InitializeParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return nil, err
+	}
+	// Respond with the requested version if supported, otherwise with the latest version we support
+	version := LatestProtocolVersion
+	if slices.Contains(SupportedProtocolVersions, params.ProtocolVersion) {
+		version = params.ProtocolVersion
+	}
+	logger.InfoContext(ctx, "Initializing MCP session", "client", params.ClientInfo.Name, "protocolVersion", version)
+	if session := SessionFromContext(ctx); session != nil {
+		session.ProtocolVersion = version
+		session.ClientCapabilities = params.Capabilities
+		session.ClientInfo = params.ClientInfo
+	}
+	return &InitializeResult{
+		ProtocolVersion: version,
+		Capabilities: ServerCapabilities{
+			Tools: &ToolsCapability{ListChanged: false},
+		},
+		ServerInfo: s.info,
+	}, nil
+}
+
+func (s *Server) listTools(ctx context.Context, rawParams json.RawMessage) (*ListToolsResult, *JSONRPCError) {
+	tools := []Tool{}
+	for _, tool := range s.registry.List() {
+		tools = append(tools, Tool{
+			Name:         tool.Name,
+			Description:  tool.Description,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+		})
+	}
+	return &ListToolsResult{Tools: tools}, nil
+}
+
+func (s *Server) callTool(ctx context.Context, rawParams json.RawMessage) (*CallToolResult, *JSONRPCError) {
+	var params CallToolParams
+	if err := unmarshalParams(rawParams, &params); err != nil {
+		return nil, err
+	}
+	if params.Name == "" {
+		return nil, &JSONRPCError{Code: InvalidParams, Message: "Tool name is required"}
+	}
+	arguments := "{}"
+	if len(params.Arguments) > 0 && string(params.Arguments) != "null" {
+		arguments = string(params.Arguments)
+	}
+
+	// Registered tools take precedence over the API details passed in _meta
+	var mcpRequest *MCPRequest
+	if tool, ok := s.registry.Get(params.Name); ok {
+		mcpRequest = tool.NewMCPRequest(arguments)
+	} else if params.Meta != nil {
+		mcpRequest = &MCPRequest{
+			ToolName:  params.Name,
+			Arguments: arguments,
+			API:       params.Meta.API,
+			Backend:   params.Meta.Backend,
+			IsProxy:   params.Meta.IsProxy,
+			Options:   params.Meta.Options,
+		}
+		if len(params.Meta.Schema) > 0 {
+			mcpRequest.Schema = string(params.Meta.Schema)
+		}
+		if len(params.Meta.OutputSchema) > 0 {
+			mcpRequest.OutputSchema = string(params.Meta.OutputSchema)
+		}
+	} else {
+		return nil, &JSONRPCError{Code: InvalidParams, Message: fmt.Sprintf("Unknown tool: %s", params.Name)}
+	}
+	if err := ValidateMCPRequest(mcpRequest); err != nil {
+		return nil, &JSONRPCError{Code: InvalidParams, Message: err.Error()}
+	}
+
+	ctx = context.WithValue(ctx, service.ToolNameKey, mcpRequest.ToolName)
+	if mcpRequest.API.APIName != "" {
+		ctx = context.WithValue(ctx, service.ApiNameKey, mcpRequest.API.APIName)
+	}
+	logger.InfoContext(ctx, "Calling underlying API", "tool_name", mcpRequest.ToolName)
+	resp, err := CallAPI(ctx, mcpRequest)
+	if err != nil {
+		// Tool execution errors are reported in the result so that the model can see them
+		logger.ErrorContext(ctx, "Failed to call underlying API", "error", err)
+		return NewToolErrorResult(err), nil
+	}
+	return NewCallToolResult(resp), nil
+}
+
+func unmarshalParams(rawParams json.RawMessage, params any) *JSONRPCError {
+	if len(rawParams) == 0 || strings.TrimSpace(string(rawParams)) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(rawParams, params); err != nil {
+		return &JSONRPCError{Code: InvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	return nil
+}
+
+func newErrorResponse(id json.RawMessage, code int, message string, data any) *JSONRPCResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &JSONRPCResponse{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error:   &JSONRPCError{Code: code, Message: message, Data: data},
+	}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestHandleMessage(t *testing.T) {
+	server := NewServer("test-server", "0.1", nil)
+	tests := []struct {
+		name       string
+		message    string
+		wantNil    bool
+		wantErr    int
+		wantResult string
+	}{
+		{
+			name:       "initialize with supported version",
+			message:    `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"client","version":"1.0"}}}`,
+			wantResult: `{"protocolVersion":"2025-03-26","capabilities":{"tools":{"listChanged":false}},"serverInfo":{"name":"test-server","version":"0.1"}}`,
+		},
+		{
+			name:       "initialize with unsupported version",
+			message:    `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+			wantResult: `{"protocolVersion":"` + LatestProtocolVersion + `","capabilities":{"tools":{"listChanged":false}},"serverInfo":{"name":"test-server","version":"0.1"}}`,
+		},
+		{
+			name:       "ping",
+			message:    `{"jsonrpc":"2.0","id":"abc","method":"ping"}`,
+			wantResult: `{}`,
+		},
+		{
+			name:    "notification",
+			message: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			wantNil: true,
+		},
+		{
+			name:    "parse error",
+			message: `{"jsonrpc":"2.0",`,
+			wantErr: ParseError,
+		},
+		{
+			name:    "invalid version",
+			message: `{"jsonrpc":"1.0","id":1,"method":"ping"}`,
+			wantErr: InvalidRequest,
+		},
+		{
+			name:    "unknown method",
+			message: `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+			wantErr: MethodNotFound,
+		},
+		{
+			name:    "tool call without name",
+			message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"arguments":{}}}`,
+			wantErr: InvalidParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := server.HandleMessage(context.Background(), []byte(tt.message))
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("HandleMessage() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("HandleMessage() = nil, want response")
+			}
+			if tt.wantErr != 0 {
+				if got.Error == nil || got.Error.Code != tt.wantErr {
+					t.Errorf("HandleMessage() error = %v, want code %d", got.Error, tt.wantErr)
+				}
+				return
+			}
+			if got.Error != nil {
+				t.Fatalf("HandleMessage() unexpected error = %v", got.Error)
+			}
+			result, _ := json.Marshal(got.Result)
+			if string(result) != tt.wantResult {
+				t.Errorf("HandleMessage() result = %s, want %s", result, tt.wantResult)
+			}
+		})
+	}
+}
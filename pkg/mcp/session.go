@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+type sessionContextKey struct{}
+
+// Session holds the state negotiated with a client.
+type Session struct {
+	ID                 string
+	ProtocolVersion    string
+	ClientCapabilities json.RawMessage
+	ClientInfo         Implementation
+	CreatedAt          time.Time
+
+	mu       sync.Mutex
+	lastSeen time.Time
+	messages chan []byte
+	done     chan struct{}
+	closed   bool
+}
+
+// SessionStore is an in-memory store of sessions that expire after being idle.
+type SessionStore struct {
+	mu          sync.RWMutex
+	sessions    map[string]*Session
+	idleTimeout time.Duration
+}
+
+func NewSessionStore(idleTimeout time.Duration) *SessionStore {
+	return &SessionStore{
+		sessions:    make(map[string]*Session),
+		idleTimeout: idleTimeout,
+	}
+}
+
+// Create creates a new session with a random session ID.
+func (store *SessionStore) Create() (*Session, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	session := NewSession(hex.EncodeToString(id))
+	store.mu.Lock()
+	store.sessions[session.ID] = session
+	store.mu.Unlock()
+	return session, nil
+}
+
+// Get returns the session with the given ID and marks it as active.
+// Returns false if the session does not exist or has expired.
+func (store *SessionStore) Get(id string) (*Session, bool) {
+	store.mu.RLock()
+	session, ok := store.sessions[id]
+	store.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if session.idleSince(time.Now()) > store.idleTimeout {
+		store.Delete(id)
+		return nil, false
+	}
+	session.Touch()
+	return session, true
+}
+
+// Delete terminates the session with the given ID.
+// Returns false if the session does not exist.
+func (store *SessionStore) Delete(id string) bool {
+	store.mu.Lock()
+	session, ok := store.sessions[id]
+	delete(store.sessions, id)
+	store.mu.Unlock()
+	if ok {
+		session.close()
+	}
+	return ok
+}
+
+// Len returns the number of active sessions.
+func (store *SessionStore) Len() int {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return len(store.sessions)
+}
+
+// StartExpiry periodically removes idle sessions until the context is cancelled.
+func (store *SessionStore) StartExpiry(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				store.expire(now)
+			}
+		}
+	}()
+}
+
+func (store *SessionStore) expire(now time.Time) {
+	store.mu.RLock()
+	var expired []string
+	for id, session := range store.sessions {
+		if session.idleSince(now) > store.idleTimeout {
+			expired = append(expired, id)
+		}
+	}
+	store.mu.RUnlock()
+	for _, id := range expired {
+		logger.Info("Expiring idle session", "sessionId", id)
+		store.Delete(id)
+	}
+}
+
+// NewSession creates a session that is not tracked by a store, such as the single session of the stdio transport.
+func NewSession(id string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:        id,
+		CreatedAt: now,
+		lastSeen:  now,
+		messages:  make(chan []byte, 16),
+		done:      make(chan struct{}),
+	}
+}
+
+// Touch marks the session as active.
+func (session *Session) Touch() {
+	session.mu.Lock()
+	session.lastSeen = time.Now()
+	session.mu.Unlock()
+}
+
+// Send queues a server initiated message to be delivered over the session's SSE stream.
+// Returns false if the session is closed or the queue is full.
+func (session *Session) Send(message []byte) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.closed {
+		return false
+	}
+	select {
+	case session.messages <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// Messages returns the channel of server initiated messages for the session.
+func (session *Session) Messages() <-chan []byte {
+	return session.messages
+}
+
+// Done returns a channel that is closed when the session is terminated.
+func (session *Session) Done() <-chan struct{} {
+	return session.done
+}
+
+func (session *Session) idleSince(now time.Time) time.Duration {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return now.Sub(session.lastSeen)
+}
+
+func (session *Session) close() {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if !session.closed {
+		session.closed = true
+		close(session.done)
+	}
+}
+
+// ContextWithSession returns a copy of the context carrying the session.
+func ContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// SessionFromContext returns the session carried by the context, if any.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return session
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionStore(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	session, err := store.Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got, ok := store.Get(session.ID); !ok || got != session {
+		t.Errorf("Get() = %v, %v, want created session", got, ok)
+	}
+	if _, ok := store.Get("unknown"); ok {
+		t.Errorf("Get() found unknown session")
+	}
+	if !store.Delete(session.ID) {
+		t.Errorf("Delete() = false, want true")
+	}
+	select {
+	case <-session.Done():
+	default:
+		t.Errorf("Delete() did not close the session")
+	}
+	if session.Send([]byte("{}")) {
+		t.Errorf("Send() on closed session = true, want false")
+	}
+	if _, ok := store.Get(session.ID); ok {
+		t.Errorf("Get() found deleted session")
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	store := NewSessionStore(time.Minute)
+	idle, _ := store.Create()
+	active, _ := store.Create()
+	idle.mu.Lock()
+	idle.lastSeen = time.Now().Add(-2 * time.Minute)
+	idle.mu.Unlock()
+
+	store.expire(time.Now())
+	if _, ok := store.Get(idle.ID); ok {
+		t.Errorf("idle session was not expired")
+	}
+	if _, ok := store.Get(active.ID); !ok {
+		t.Errorf("active session was expired")
+	}
+	if store.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", store.Len())
+	}
+}
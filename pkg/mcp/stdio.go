@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+const maxStdioMessageSize = 10 * 1024 * 1024
+
+// ServeStdio reads newline-delimited JSON-RPC messages from the reader and writes the responses
+// to the writer, one per line, until the reader is exhausted.
+// Requests are handled concurrently so that a slow tool call does not block other messages.
+func (s *Server) ServeStdio(ctx context.Context, reader io.Reader, writer io.Writer) error {
+	ctx = ContextWithSession(ctx, NewSession("stdio"))
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStdioMessageSize)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	encoder := json.NewEncoder(writer)
+	for scanner.Scan() {
+		message := bytes.TrimSpace(scanner.Bytes())
+		if len(message) == 0 {
+			continue
+		}
+		// The scanner reuses its buffer, so the message has to be copied before handing it off
+		message = bytes.Clone(message)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := s.HandleMessage(ctx, message)
+			if response == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err := encoder.Encode(response); err != nil {
+				logger.ErrorContext(ctx, "Failed to write JSON-RPC response", "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return scanner.Err()
+}
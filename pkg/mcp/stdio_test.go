@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestServeStdio(t *testing.T) {
+	server := NewServer("test-server", "0.1", nil)
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n")
+	var output bytes.Buffer
+
+	if err := server.ServeStdio(context.Background(), strings.NewReader(input), &output); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("ServeStdio() wrote %d responses, want 2: %s", len(lines), output.String())
+	}
+	ids := map[string]bool{}
+	for _, line := range lines {
+		var response JSONRPCResponse
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("invalid response %s: %v", line, err)
+		}
+		if response.Error != nil {
+			t.Errorf("unexpected error response %s", line)
+		}
+		ids[string(response.ID)] = true
+	}
+	if !ids["1"] || !ids["2"] {
+		t.Errorf("ServeStdio() responses = %v, want ids 1 and 2", lines)
+	}
+}
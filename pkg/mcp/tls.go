@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"mcp-server/pkg/service"
+	"os"
+)
+
+// tlsVersions maps the configured minimum TLS versions to their protocol versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsProfiles are the TLS configurations of the backends, selected by profile name or by host.
+type tlsProfiles struct {
+	configs map[string]*tls.Config
+	// errs are the errors of profiles that failed to load, whose calls fail instead of falling
+	// back to the default TLS configuration
+	errs  map[string]error
+	hosts *hostPatterns
+}
+
+// newTLSProfiles loads the certificates of the TLS profiles. Profiles that fail to load are logged
+// and fail the calls that use them.
+func newTLSProfiles(profiles map[string]service.TLSProfile) *tlsProfiles {
+	result := &tlsProfiles{
+		configs: make(map[string]*tls.Config, len(profiles)),
+		errs:    make(map[string]error),
+		hosts:   newHostPatterns(),
+	}
+	for name, profile := range profiles {
+		config, err := newTLSConfig(profile)
+		if err != nil {
+			logger.Error("Failed to load TLS profile", "profile", name, "error", err)
+			result.errs[name] = fmt.Errorf("failed to load tls profile %s: %v", name, err)
+		} else {
+			result.configs[name] = config
+		}
+		for _, host := range profile.Hosts {
+			result.hosts.add(host, name)
+		}
+	}
+	return result
+}
+
+// newTLSConfig creates the TLS configuration of a profile.
+func newTLSConfig(profile service.TLSProfile) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         profile.ServerName,
+		InsecureSkipVerify: profile.Insecure,
+	}
+	if profile.MinVersion != "" {
+		version, ok := tlsVersions[profile.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported minimum TLS version: %s", profile.MinVersion)
+		}
+		config.MinVersion = version
+	}
+	if profile.CAFile != "" {
+		bundle, err := os.ReadFile(profile.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", profile.CAFile)
+		}
+	}
+	if profile.CertFile != "" || profile.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(profile.CertFile, profile.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// profile returns the name and TLS configuration of the profile used for a host, which is the
+// tls_profile option of the tool or the profile of the host. Returns an empty name and a nil
+// configuration for the default TLS configuration.
+func (profiles *tlsProfiles) profile(host string, options ToolOptions) (string, *tls.Config, error) {
+	name := options.TLSProfile
+	if name == "" {
+		name = profiles.hosts.match(host)
+		if name == "" {
+			return "", nil, nil
+		}
+	}
+	if err, failed := profiles.errs[name]; failed {
+		return "", nil, err
+	}
+	config, ok := profiles.configs[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown tls profile: %s", name)
+	}
+	return name, config, nil
+}
+
+// validateTLSProfile checks that the TLS profile of a tool is configured.
+func validateTLSProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	if config := service.GetConfig(); config != nil {
+		if _, ok := config.Http.TLSProfiles[name]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tls profile: %s", name)
+}
@@ -0,0 +1,226 @@
+package mcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"mcp-server/pkg/service"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeClientCertificate writes a self-signed client certificate and its key to the directory.
+func writeClientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mcp-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certificate, certFile, keyFile
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCallAPITLSProfile(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCertificate(t, dir)
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeJSON)
+		w.Write([]byte(`{"client":"` + r.TLS.PeerCertificates[0].Subject.CommonName + `","server_name":"` + r.TLS.ServerName + `"}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	backend.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	backend.StartTLS()
+	defer backend.Close()
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", backend.Certificate().Raw)
+	host := strings.TrimPrefix(backend.URL, "https://")
+
+	client := InitHttpClient()
+	profiles := client.tlsProfiles
+	defer func() { client.tlsProfiles = profiles }()
+	mtls := service.TLSProfile{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2", ServerName: "example.com"}
+
+	tests := []struct {
+		name       string
+		profiles   map[string]service.TLSProfile
+		tlsProfile string
+		wantErr    string
+		wantBody   string
+	}{
+		{
+			name:    "default configuration does not trust the backend",
+			wantErr: "certificate",
+		},
+		{
+			name:       "profile of the tool",
+			profiles:   map[string]service.TLSProfile{"internal": mtls},
+			tlsProfile: "internal",
+			wantBody:   `{"client":"mcp-client","server_name":"example.com"}`,
+		},
+		{
+			name:     "profile of the host",
+			profiles: map[string]service.TLSProfile{"by-host": withHosts(mtls, host)},
+			wantBody: `{"client":"mcp-client","server_name":"example.com"}`,
+		},
+		{
+			name:     "server name must match the certificate",
+			profiles: map[string]service.TLSProfile{"other-name": withServerName(withHosts(mtls, host), "other.example.org")},
+			wantErr:  "other.example.org",
+		},
+		{
+			name:     "client certificate is required",
+			profiles: map[string]service.TLSProfile{"no-client-cert": {Hosts: []string{host}, CAFile: caFile, ServerName: "example.com"}},
+			wantErr:  "certificate",
+		},
+		{
+			name:     "profile that failed to load is not replaced by the default",
+			profiles: map[string]service.TLSProfile{"broken": {Hosts: []string{host}, CAFile: filepath.Join(dir, "missing.pem")}},
+			wantErr:  "failed to load tls profile broken",
+		},
+		{
+			name:       "unknown profile",
+			tlsProfile: "missing",
+			wantErr:    "unknown tls profile: missing",
+		},
+	}
+	// Transports are cached by profile name, so each case names its profile differently
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.tlsProfiles = newTLSProfiles(tt.profiles)
+			request := newBackendRequest(backend.URL, ToolOptions{TLSProfile: tt.tlsProfile, Retry: &RetryOptions{MaxAttempts: 1}})
+			resp, err := CallAPI(context.Background(), request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CallAPI() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CallAPI() error = %v", err)
+			}
+			if resp.Text != tt.wantBody {
+				t.Errorf("CallAPI() = %s, want %s", resp.Text, tt.wantBody)
+			}
+		})
+	}
+}
+
+func withHosts(profile service.TLSProfile, hosts ...string) service.TLSProfile {
+	profile.Hosts = hosts
+	return profile
+}
+
+func withServerName(profile service.TLSProfile, serverName string) service.TLSProfile {
+	profile.ServerName = serverName
+	return profile
+}
+
+func TestHostPatternsMatch(t *testing.T) {
+	patterns := newHostPatterns()
+	patterns.add("api.example.com:8443", "port")
+	patterns.add("API.example.com", "host")
+	patterns.add("*.example.com", "wildcard")
+	patterns.add("*.internal.example.com", "nested")
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "api.example.com:8443", want: "port"},
+		{host: "api.example.com", want: "host"},
+		{host: "Api.Example.com:443", want: "host"},
+		{host: "orders.example.com", want: "wildcard"},
+		{host: "orders.internal.example.com:9443", want: "nested"},
+		{host: "example.com", want: ""},
+		{host: "127.0.0.1:8080", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := patterns.match(tt.host); got != tt.want {
+				t.Errorf("match(%s) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := writeClientCertificate(t, dir)
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		profile        service.TLSProfile
+		wantErr        string
+		wantMinVersion uint16
+	}{
+		{name: "minimum version", profile: service.TLSProfile{MinVersion: "1.3"}, wantMinVersion: tls.VersionTLS13},
+		{name: "client certificate", profile: service.TLSProfile{CertFile: certFile, KeyFile: keyFile}},
+		{name: "unsupported version", profile: service.TLSProfile{MinVersion: "1.4"}, wantErr: "unsupported minimum TLS version: 1.4"},
+		{name: "CA bundle without certificates", profile: service.TLSProfile{CAFile: empty}, wantErr: "no certificates found"},
+		{name: "invalid key", profile: service.TLSProfile{CertFile: certFile, KeyFile: empty}, wantErr: "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newTLSConfig(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newTLSConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTLSConfig() error = %v", err)
+			}
+			if config.MinVersion != tt.wantMinVersion {
+				t.Errorf("newTLSConfig() MinVersion = %x, want %x", config.MinVersion, tt.wantMinVersion)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
@@ -22,6 +23,16 @@ func transformMCPRequest(mcpRequest *MCPRequest) (*TransformedRequest, error) {
 	}
 	httpRequest.Method = method
 
+	args, err := parseArgs(mcpRequest)
+	if err != nil {
+		logger.Error("Failed to parse arguments", "error", err)
+		return nil, err
+	}
+	if err := validateArguments(mcpRequest, args); err != nil {
+		logger.Error("Arguments do not match the input schema", "error", err)
+		return nil, err
+	}
+
 	schemaMapping, err := processSchema(mcpRequest.Schema)
 	if err != nil {
 		logger.Error("Failed to process schema", "error", err)
@@ -43,13 +54,14 @@ func transformMCPRequest(mcpRequest *MCPRequest) (*TransformedRequest, error) {
 	httpRequest.Headers = headers
 
 	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
-		bytesReader, err := processRequestBody(mcpRequest, schemaMapping)
+		bytesReader, contentType, err := processRequestBody(mcpRequest, schemaMapping)
 		if err != nil {
 			logger.Error("Failed to process request body", "error", err)
 			return nil, err
 		}
 		if bytesReader != nil {
 			httpRequest.Body = bytesReader
+			httpRequest.Headers[ContentType] = contentType
 		} else {
 			logger.Warn("Request body is nil")
 		}
@@ -79,7 +91,7 @@ func processHTTPMethod(mcpRequest *MCPRequest) (string, error) {
 	case "OPTIONS":
 		return http.MethodOptions, nil
 	default:
-		return "", fmt.Errorf("unsupported HTTP method: %s", method)
+		return "", fmt.Errorf("%w: unsupported HTTP method: %s", ErrInvalidAPIDetails, method)
 	}
 }
 
@@ -100,7 +112,7 @@ func processEndpoint(mcpRequest *MCPRequest, schemaMapping *SchemaMapping) (stri
 	}
 	_, err = url.ParseRequestURI(endpoint)
 	if err != nil {
-		return "", fmt.Errorf("invalid endpoint: %s", endpoint)
+		return "", fmt.Errorf("%w: invalid endpoint: %s", ErrInvalidAPIDetails, endpoint)
 	}
 	transformedEp := ""
 	sanitizedEp := sanitizeStringSlashes(endpoint)
@@ -131,29 +143,29 @@ func processEndpoint(mcpRequest *MCPRequest, schemaMapping *SchemaMapping) (stri
 }
 
 // processQueryParameters generates a query string from the provided arguments and schema mapping.
-// It URL-encodes parameter names and values and appends them to the query string.
+// It URL-encodes parameter names and values, serializing arrays and objects following the
+// parameter style, and appends them to the query string.
 // Returns the constructed query string or an error if required parameters are missing.
 func processQueryParameters(args map[string]any, schemaMapping *SchemaMapping) (string, error) {
 	queryParams := schemaMapping.QueryParameters
 	if len(queryParams) > 0 {
-		queryString := "?"
+		var pairs []string
 		for _, param := range queryParams {
 			paramName := param.Name
-			paramValue := args[paramName]
+			paramValue := lookupParam(args, param)
 			if param.Required && paramValue == nil {
 				logger.Error("Required query parameter value is not available", "parameter", paramName)
-				return "", fmt.Errorf("required query parameter %s is missing", paramName)
+				return "", &MissingParameterError{Location: LocationQuery, Name: paramName}
 			} else if paramValue == nil {
 				logger.Warn("Query parameter value is not available", "parameter", paramName)
 				continue
 			}
-			// URL encode the parameter name and value
-			urlEncodedParam := url.PathEscape(fmt.Sprintf("%v", paramName))
-			urlEncodedValue := url.PathEscape(fmt.Sprintf("%v", paramValue))
-			queryString += fmt.Sprintf("%s=%s&", urlEncodedParam, urlEncodedValue)
+			// URL encode the parameter name and values following the parameter style
+			pairs = append(pairs, serializeQueryParam(param, paramValue)...)
+		}
+		if len(pairs) > 0 {
+			return "?" + strings.Join(pairs, "&"), nil
 		}
-		queryString = strings.TrimSuffix(queryString, "&")
-		return queryString, nil
 	}
 	return "", nil
 }
@@ -166,14 +178,14 @@ func processPathParameters(args map[string]any, schemaMapping *SchemaMapping, un
 	transformedUrl := unProcessedUrl
 	if len(pathParams) > 0 {
 		for _, param := range pathParams {
-			paramValue := args[param]
+			paramValue := lookupParam(args, param)
 			if paramValue == nil {
-				logger.Error("Path parameter value is not available", "parameter", param)
-				return "", fmt.Errorf("path parameter %s is missing", param)
+				logger.Error("Path parameter value is not available", "parameter", param.Name)
+				return "", &MissingParameterError{Location: LocationPath, Name: param.Name}
 			}
-			// URL encode the parameter name and value
-			urlEncodedParam := url.PathEscape(fmt.Sprintf("%v", param))
-			urlEncodedValue := url.PathEscape(fmt.Sprintf("%v", paramValue))
+			// URL encode the parameter name and value following the parameter style
+			urlEncodedParam := url.PathEscape(param.Name)
+			urlEncodedValue := serializePathParam(param, paramValue)
 			processedUrl := strings.Replace(transformedUrl, "{"+urlEncodedParam+"}", urlEncodedValue, 1)
 			transformedUrl = processedUrl
 		}
@@ -182,6 +194,7 @@ func processPathParameters(args map[string]any, schemaMapping *SchemaMapping, un
 }
 
 // processHeaderParameters generates a map of header parameters from the provided arguments and schema mapping.
+// Cookie parameters are collected into the Cookie header.
 // Returns a map of header names and values.
 func processHeaderParameters(mcpRequest *MCPRequest, schemaMapping *SchemaMapping) (map[string]string, error) {
 	args, err := parseArgs(mcpRequest)
@@ -194,17 +207,28 @@ func processHeaderParameters(mcpRequest *MCPRequest, schemaMapping *SchemaMappin
 	if len(headerParams) > 0 {
 		for _, param := range headerParams {
 			paramName := param.Name
-			paramValue := args[paramName]
+			paramValue := lookupParam(args, param)
 			if param.Required && paramValue == nil {
-				logger.Error("Required query parameter value is not available", "parameter", paramName)
-				return nil, fmt.Errorf("required query parameter %s is missing", paramName)
+				logger.Error("Required header parameter value is not available", "parameter", paramName)
+				return nil, &MissingParameterError{Location: LocationHeader, Name: paramName}
 			} else if paramValue == nil {
-				logger.Warn("Query parameter value is not available", "parameter", paramName)
+				logger.Warn("Header parameter value is not available", "parameter", paramName)
 				continue
 			}
-			headers[paramName] = fmt.Sprintf("%v", paramValue)
+			headers[paramName] = serializeHeaderParam(param, paramValue)
 		}
 	}
+	cookies, err := processCookieParameters(args, schemaMapping)
+	if err != nil {
+		return nil, err
+	}
+	if cookies != "" {
+		// Keep cookies already sent through a header parameter
+		if existing := headers[HeaderCookie]; existing != "" {
+			cookies = existing + "; " + cookies
+		}
+		headers[HeaderCookie] = cookies
+	}
 	// Add authentication header if provided
 	if mcpRequest.API.Auth != "" {
 		k, v, found := strings.Cut(mcpRequest.API.Auth, ":")
@@ -221,45 +245,107 @@ func processHeaderParameters(mcpRequest *MCPRequest, schemaMapping *SchemaMappin
 	return headers, nil
 }
 
+// processCookieParameters generates the value of the Cookie header from the provided arguments and schema mapping.
+// Returns the cookie pairs separated by semicolons or an error if required parameters are missing.
+func processCookieParameters(args map[string]any, schemaMapping *SchemaMapping) (string, error) {
+	var pairs []string
+	for _, param := range schemaMapping.CookieParameters {
+		paramName := param.Name
+		if !isCookieName(paramName) {
+			logger.Error("Invalid cookie parameter name", "parameter", paramName)
+			return "", fmt.Errorf("%w: invalid cookie parameter name %q", ErrInvalidAPIDetails, paramName)
+		}
+		paramValue := lookupParam(args, param)
+		if param.Required && paramValue == nil {
+			logger.Error("Required cookie parameter value is not available", "parameter", paramName)
+			return "", &MissingParameterError{Location: LocationCookie, Name: paramName}
+		} else if paramValue == nil {
+			logger.Warn("Cookie parameter value is not available", "parameter", paramName)
+			continue
+		}
+		cookiePairs, err := serializeCookieParam(param, paramValue)
+		if err != nil {
+			logger.Error("Invalid cookie parameter value", "parameter", paramName, "error", err)
+			return "", err
+		}
+		pairs = append(pairs, cookiePairs...)
+	}
+	return strings.Join(pairs, "; "), nil
+}
+
 // processRequestBody processes the request body from the MCP request.
-// Returns a bytes.Reader for the request body or an error if the body is invalid.
-func processRequestBody(mcpRequest *MCPRequest, schemaMapping *SchemaMapping) (*bytes.Reader, error) {
+// Returns a bytes.Reader for the request body and the content type to send it with, which differs
+// from the schema content type for multipart bodies, or an error if the body is invalid.
+func processRequestBody(mcpRequest *MCPRequest, schemaMapping *SchemaMapping) (*bytes.Reader, string, error) {
 	contentType := schemaMapping.ContentType
 	args, err := parseArgs(mcpRequest)
 	if err != nil {
 		logger.Error("Failed to parse arguments", "error", err)
-		return nil, err
+		return nil, "", err
 	}
 
-	var body map[string]any
-	if args["requestBody"] != nil {
-		body = args["requestBody"].(map[string]any)
-		if contentType == ContentTypeJSON {
+	bodyProperty := schemaMapping.BodyProperty
+	if bodyProperty == "" {
+		bodyProperty = "requestBody"
+	}
+	if args[bodyProperty] != nil {
+		body := args[bodyProperty]
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			logger.Error("Invalid content type", "contentType", contentType, "error", err)
+			return nil, "", fmt.Errorf("%w: invalid content type: %s", ErrInvalidAPIDetails, contentType)
+		}
+		switch mediaType {
+		case ContentTypeJSON:
 			jsonString, err := json.Marshal(body)
 			if err != nil {
 				logger.Error("Failed to marshal request body", "error", err)
-				return nil, err
+				return nil, "", err
 			}
 			byteArray := []byte(jsonString)
 			bodyReader := bytes.NewReader(byteArray)
-			return bodyReader, nil
-		} else if contentType == ContentTypeXML {
-			//todo: Need to figure out how to handle the root element name
-			root := XMLElement{XMLName: xml.Name{Local: "Body"}, Children: mapToXMLElements(body)}
+			return bodyReader, contentType, nil
+		case ContentTypeXML:
+			bodyMap, ok := body.(map[string]any)
+			if !ok {
+				return nil, "", fmt.Errorf("%w: request body must be an object to be sent as XML", ErrInvalidArguments)
+			}
+			bodySchema, err := parseXMLBodySchema(mcpRequest.Schema, bodyProperty)
+			if err != nil {
+				logger.Warn("Failed to read the XML metadata of the request body schema", "error", err)
+			}
+			root := buildXMLBody(bodySchema, bodyMap)
 			xmlString, err := xml.MarshalIndent(root, "", "  ")
 			if err != nil {
 				logger.Error("Failed to marshal request body", "error", err)
-				return nil, err
+				return nil, "", err
 			}
 			byteArray := []byte(xmlString)
 			bodyReader := bytes.NewReader(byteArray)
-			return bodyReader, nil
-		} else {
+			return bodyReader, contentType, nil
+		case ContentTypeForm:
+			bodyMap, ok := body.(map[string]any)
+			if !ok {
+				return nil, "", fmt.Errorf("%w: request body must be an object to be sent as a form", ErrInvalidArguments)
+			}
+			return bytes.NewReader(encodeFormBody(bodyMap)), contentType, nil
+		case ContentTypeMultipart:
+			bodyMap, ok := body.(map[string]any)
+			if !ok {
+				return nil, "", fmt.Errorf("%w: request body must be an object to be sent as multipart form data", ErrInvalidArguments)
+			}
+			byteArray, multipartType, err := encodeMultipartBody(bodyMap)
+			if err != nil {
+				logger.Error("Failed to encode multipart request body", "error", err)
+				return nil, "", err
+			}
+			return bytes.NewReader(byteArray), multipartType, nil
+		default:
 			logger.Error("Unsupported content type", "contentType", contentType)
-			return nil, fmt.Errorf("unsupported content type: %s", contentType)
+			return nil, "", fmt.Errorf("%w: unsupported content type: %s", ErrInvalidAPIDetails, contentType)
 		}
 	}
-	return nil, nil
+	return nil, "", nil
 }
 
 func parseArgs(mcpRequest *MCPRequest) (map[string]any, error) {
@@ -268,12 +354,26 @@ func parseArgs(mcpRequest *MCPRequest) (map[string]any, error) {
 		err := json.Unmarshal([]byte(mcpRequest.Arguments), &args)
 		if err != nil {
 			logger.Error("Failed to unmarshal arguments", "error", err)
-			return nil, err
+			return nil, fmt.Errorf("%w: arguments must be a JSON object: %v", ErrInvalidArguments, err)
 		}
 	}
 	return args, nil
 }
 
+// lookupParam returns the value of a parameter from the arguments.
+// Values are looked up by the input property name, and by the parameter name for prefixed properties.
+func lookupParam(args map[string]any, param Param) any {
+	if param.Property != "" {
+		if value, ok := args[param.Property]; ok {
+			return value
+		}
+		if !param.Prefixed {
+			return nil
+		}
+	}
+	return args[param.Name]
+}
+
 func sanitizeStringSlashes(input string) string {
 	// Remove any trailing or preceding slashes from the input
 	return strings.TrimSuffix(strings.TrimPrefix(input, "/"), "/")
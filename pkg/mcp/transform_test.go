@@ -85,6 +85,22 @@ func TestProcessQueryParameters(t *testing.T) {
 			wantQuery: "?foo=bar%20baz&baz=qux%21",
 			wantErr:   false,
 		},
+		{
+			name: "query delimiters in values",
+			mcpRequest: &MCPRequest{
+				Arguments: `{"q":"a&admin=true","sum":"1+1=2","tags":["c#","x&y"],"filter":{"a=b":"c+d"}}`,
+			},
+			schema: &SchemaMapping{
+				QueryParameters: []Param{
+					{Name: "q"},
+					{Name: "sum"},
+					{Name: "tags", Explode: boolPtr(false)},
+					{Name: "filter", Style: StyleDeepObject},
+				},
+			},
+			wantQuery: "?q=a%26admin%3Dtrue&sum=1%2B1%3D2&tags=c%23,x%26y&filter[a%3Db]=c%2Bd",
+			wantErr:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -200,6 +216,56 @@ func TestProcessHeaderParameters(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "cookie parameters",
+			mcpRequest: &MCPRequest{
+				Arguments: `{"cookie_session":"a b;c","theme":"dark","Cookie":"lang=en"}`,
+				API:       APIInfo{},
+			},
+			schema: &SchemaMapping{
+				HeaderParameters: []Param{
+					{Name: "Cookie"},
+				},
+				CookieParameters: []Param{
+					{Name: "session", Required: true, Property: "cookie_session", Prefixed: true},
+					{Name: "theme", Property: "theme"},
+					{Name: "tracking", Property: "cookie_tracking", Prefixed: true},
+				},
+			},
+			wantHeaders: map[string]string{
+				"Cookie":       "lang=en; session=a%20b%3Bc; theme=dark",
+				"Content-Type": ContentTypeJSON,
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing required cookie",
+			mcpRequest: &MCPRequest{
+				Arguments: `{"theme":"dark"}`,
+				API:       APIInfo{},
+			},
+			schema: &SchemaMapping{
+				CookieParameters: []Param{
+					{Name: "session", Required: true},
+				},
+			},
+			wantHeaders: nil,
+			wantErr:     true,
+		},
+		{
+			name: "invalid cookie name",
+			mcpRequest: &MCPRequest{
+				Arguments: `{"my session":"abc"}`,
+				API:       APIInfo{},
+			},
+			schema: &SchemaMapping{
+				CookieParameters: []Param{
+					{Name: "my session"},
+				},
+			},
+			wantHeaders: nil,
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -227,8 +293,8 @@ func TestProcessPathParameters(t *testing.T) {
 				Arguments: `{"foo":"bar","baz":"qux"}`,
 			},
 			schema: &SchemaMapping{
-				PathParameters: []string{
-					"foo", "baz",
+				PathParameters: []Param{
+					{Name: "foo", Required: true}, {Name: "baz", Required: true},
 				},
 			},
 			wantPath: "https://test.com/bar/test/qux",
@@ -240,8 +306,8 @@ func TestProcessPathParameters(t *testing.T) {
 				Arguments: `{"foo":"bar"}`,
 			},
 			schema: &SchemaMapping{
-				PathParameters: []string{
-					"foo", "baz",
+				PathParameters: []Param{
+					{Name: "foo", Required: true}, {Name: "baz", Required: true},
 				},
 			},
 			wantPath: "",
@@ -253,7 +319,7 @@ func TestProcessPathParameters(t *testing.T) {
 				Arguments: `{}`,
 			},
 			schema: &SchemaMapping{
-				PathParameters: []string{},
+				PathParameters: []Param{},
 			},
 			wantPath: "https://test.com/{foo}/test/{baz}",
 			wantErr:  false,
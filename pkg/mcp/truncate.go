@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncateResponse cuts a text response down to at most limit bytes.
+// JSON responses are cut after the last complete array element or object member and the open
+// arrays and objects are closed, so that the result is still valid JSON. CSV responses are cut
+// after the last complete line and other responses at a character boundary.
+func truncateResponse(body []byte, contentType string, options ToolOptions, limit int) []byte {
+	if len(body) <= limit {
+		return body
+	}
+	if !options.RawResponse {
+		switch responseFormat(contentType) {
+		case formatJSON:
+			if truncated, ok := truncateJSON(body, limit); ok {
+				return truncated
+			}
+		case formatCSV:
+			if i := bytes.LastIndexByte(body[:limit], '\n'); i >= 0 {
+				return body[:i+1]
+			}
+		}
+	}
+	return truncateText(body, limit)
+}
+
+// truncateJSON cuts a JSON document at the last point before the limit where the closing brackets
+// of the open arrays and objects can be appended. Returns false if there is no such point, such as
+// for a single long string.
+func truncateJSON(data []byte, limit int) ([]byte, bool) {
+	var stack []byte
+	var closing []byte
+	cut := -1
+	inString := false
+	escaped := false
+	// mark records a point at which the document can be cut if it fits within the limit
+	mark := func(position int) {
+		if position+len(stack) <= limit {
+			cut = position
+			closing = closing[:0]
+			for i := len(stack) - 1; i >= 0; i-- {
+				closing = append(closing, stack[i])
+			}
+		}
+	}
+	for i := 0; i < len(data) && i < limit; i++ {
+		c := data[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+			mark(i + 1)
+		case '}', ']':
+			if len(stack) == 0 {
+				return nil, false
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				mark(i + 1)
+			}
+		case ',':
+			mark(i)
+		}
+	}
+	if cut < 0 {
+		return nil, false
+	}
+	truncated := make([]byte, 0, cut+len(closing))
+	truncated = append(truncated, data[:cut]...)
+	return append(truncated, closing...), true
+}
+
+// truncateText cuts text at the last character boundary before the limit.
+func truncateText(data []byte, limit int) []byte {
+	end := limit
+	for end > 0 && end > limit-utf8.UTFMax && !utf8.RuneStart(data[end]) {
+		end--
+	}
+	return data[:end]
+}
+
+// truncationNotice tells the model that a response was truncated and how to get the rest.
+func truncationNotice(resp *APIResponse) string {
+	notice := fmt.Sprintf("The response was truncated to %d bytes", len(resp.Body))
+	if resp.Size > 0 {
+		notice += fmt.Sprintf(" of %d bytes", resp.Size)
+	}
+	return notice + ". Request less data, for example a smaller page or a narrower filter, to get the rest."
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import "testing"
+
+func TestTruncateResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		options     ToolOptions
+		limit       int
+		want        string
+	}{
+		{
+			name:        "within the limit",
+			body:        `{"id":1}`,
+			contentType: ContentTypeJSON,
+			limit:       8,
+			want:        `{"id":1}`,
+		},
+		{
+			name:        "json array elements",
+			body:        `[{"id":1},{"id":2},{"id":3}]`,
+			contentType: ContentTypeJSON,
+			limit:       22,
+			want:        `[{"id":1},{"id":2}]`,
+		},
+		{
+			name:        "json nested array",
+			body:        `{"items":[{"id":1,"name":"alpha"},{"id":2,"name":"beta"}],"next":"abc"}`,
+			contentType: ContentTypeJSON,
+			limit:       48,
+			want:        `{"items":[{"id":1,"name":"alpha"},{"id":2}]}`,
+		},
+		{
+			name:        "json string with brackets",
+			body:        `["a,]}", "b,]}", "c,]}"]`,
+			contentType: "application/problem+json",
+			limit:       16,
+			want:        `["a,]}", "b,]}"]`,
+		},
+		{
+			name:        "json without a boundary",
+			body:        `"a long string"`,
+			contentType: ContentTypeJSON,
+			limit:       6,
+			want:        `"a lon`,
+		},
+		{
+			name:        "csv lines",
+			body:        "id,name\n1,alpha\n2,beta\n",
+			contentType: "text/csv",
+			limit:       20,
+			want:        "id,name\n1,alpha\n",
+		},
+		{
+			name:        "raw response is cut as text",
+			body:        `[1,2,3]`,
+			contentType: ContentTypeJSON,
+			options:     ToolOptions{RawResponse: true},
+			limit:       4,
+			want:        `[1,2`,
+		},
+		{
+			name:        "text at a character boundary",
+			body:        "héllo",
+			contentType: "text/plain",
+			limit:       2,
+			want:        "h",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(truncateResponse([]byte(tt.body), tt.contentType, tt.options, tt.limit))
+			if got != tt.want {
+				t.Errorf("truncateResponse() = %s, want %s", got, tt.want)
+			}
+			if len(got) > tt.limit {
+				t.Errorf("truncateResponse() returned %d bytes, limit is %d", len(got), tt.limit)
+			}
+		})
+	}
+}
@@ -2,16 +2,91 @@ package mcp
 
 import (
 	"bytes"
+	"encoding/json"
 	"encoding/xml"
 )
 
 type MCPRequest struct {
-	ToolName  string      `json:"tool_name"`
-	Arguments string      `json:"arguments,omitempty"`
-	Schema    string      `json:"schema,omitempty"`
-	API       APIInfo     `json:"api"`
-	Backend   BackendInfo `json:"backend,omitempty"`
-	IsProxy   bool        `json:"is_proxy,omitempty"`
+	ToolName     string      `json:"tool_name"`
+	Arguments    string      `json:"arguments,omitempty"`
+	Schema       string      `json:"schema,omitempty"`
+	OutputSchema string      `json:"output_schema,omitempty"`
+	API          APIInfo     `json:"api"`
+	Backend      BackendInfo `json:"backend,omitempty"`
+	IsProxy      bool        `json:"is_proxy,omitempty"`
+	Options      ToolOptions `json:"options,omitempty"`
+	// projection is the compiled projection of registered tools, which requests carrying their
+	// own options compile per call
+	projection *responseProjection
+	// patterns are the compiled schema patterns of registered tools
+	patterns schemaPatterns
+}
+
+// ToolOptions controls how the call to the underlying API of a tool is made and how its response
+// is returned.
+type ToolOptions struct {
+	// RawResponse returns the response body as received instead of converting XML, CSV and YAML
+	// responses to JSON
+	RawResponse bool `json:"raw_response,omitempty"`
+	// StrictOutput turns responses that do not match the output schema into tool errors instead of
+	// only logging the mismatch
+	StrictOutput bool `json:"strict_output,omitempty"`
+	// MaxBinarySize overrides the maximum size in bytes of binary responses
+	MaxBinarySize int64 `json:"max_binary_size,omitempty"`
+	// MaxResponseSize overrides the maximum size in bytes of text responses, which are truncated
+	// past it
+	MaxResponseSize int64 `json:"max_response_size,omitempty"`
+	// ResponseMapping is a JSONPath expression starting with $ or a JMESPath expression that
+	// selects the part of a JSON response returned by the tool
+	ResponseMapping string `json:"response_mapping,omitempty"`
+	// IncludeFields are the dotted names of the response fields to keep
+	IncludeFields []string `json:"include_fields,omitempty"`
+	// ExcludeFields are the dotted names of the response fields to drop
+	ExcludeFields []string `json:"exclude_fields,omitempty"`
+	// Pagination follows the pages of list responses and merges their items
+	Pagination *PaginationOptions `json:"pagination,omitempty"`
+	// Timeout overrides the overall timeout in seconds of a call, including all of its pages
+	Timeout int `json:"timeout,omitempty"`
+	// ConnectTimeout overrides the timeout in seconds of connecting to the underlying API
+	ConnectTimeout int `json:"connect_timeout,omitempty"`
+	// TLSHandshakeTimeout overrides the timeout in seconds of the TLS handshake
+	TLSHandshakeTimeout int `json:"tls_handshake_timeout,omitempty"`
+	// ResponseHeaderTimeout overrides the timeout in seconds of waiting for the response headers
+	ResponseHeaderTimeout int `json:"response_header_timeout,omitempty"`
+	// Retry overrides the retry policy of calls to the underlying API
+	Retry *RetryOptions `json:"retry,omitempty"`
+	// TLSProfile is the name of the configured TLS profile used to connect to the underlying API,
+	// overriding the profile selected by its host
+	TLSProfile string `json:"tls_profile,omitempty"`
+}
+
+// RetryOptions overrides the configured retry policy for a tool.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts of a request, 1 disables retries
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// InitialBackoff is the maximum delay in milliseconds before the first retry, which doubles
+	// for each retry
+	InitialBackoff int `json:"initial_backoff,omitempty"`
+	// MaxBackoff is the maximum delay in milliseconds before a retry
+	MaxBackoff int `json:"max_backoff,omitempty"`
+	// StatusCodes are the status codes of responses that are retried
+	StatusCodes []int `json:"status_codes,omitempty"`
+	// NonIdempotent allows retrying POST and PATCH requests
+	NonIdempotent bool `json:"non_idempotent,omitempty"`
+}
+
+// PaginationOptions describes how the pages of a list response are requested.
+type PaginationOptions struct {
+	// Type is next_link, link_header, cursor, offset or page
+	Type string `json:"type"`
+	// ItemsPath is the dotted name of the items array in the response, empty for an array response
+	ItemsPath string `json:"items_path,omitempty"`
+	// NextPath is the dotted name of the next page link or cursor in the response
+	NextPath string `json:"next_path,omitempty"`
+	// Param is the query parameter carrying the cursor, offset or page number
+	Param string `json:"param,omitempty"`
+	// MaxPages is the maximum number of pages fetched by a call
+	MaxPages int `json:"max_pages,omitempty"`
 }
 
 type APIInfo struct {
@@ -30,6 +105,23 @@ type BackendInfo struct {
 	Verb     string `json:"verb"`
 }
 
+// ToolDefinition describes a tool along with the API it is backed by.
+type ToolDefinition struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  json.RawMessage `json:"inputSchema"`
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	API          *APIInfo        `json:"api,omitempty"`
+	Backend      *BackendInfo    `json:"backend,omitempty"`
+	IsProxy      bool            `json:"is_proxy,omitempty"`
+	Options      ToolOptions     `json:"options,omitempty"`
+	// projection is the projection of the options, compiled when the tool is registered
+	projection *responseProjection
+	// patterns are the patterns of the input and output schemas, compiled when the tool is
+	// registered
+	patterns schemaPatterns
+}
+
 type TransformedRequest struct {
 	Method  string
 	URL     string
@@ -38,16 +130,26 @@ type TransformedRequest struct {
 }
 
 type SchemaMapping struct {
-	PathParameters   []string `json:"pathParameters"`
-	QueryParameters  []Param  `json:"queryParameters"`
-	HeaderParameters []Param  `json:"headerParameters"`
-	HasBody          bool     `json:"hasBody"`
-	ContentType      string   `json:"contentType,omitempty"`
+	PathParameters   []Param `json:"pathParameters"`
+	QueryParameters  []Param `json:"queryParameters"`
+	HeaderParameters []Param `json:"headerParameters"`
+	CookieParameters []Param `json:"cookieParameters,omitempty"`
+	HasBody          bool    `json:"hasBody"`
+	BodyProperty     string  `json:"bodyProperty,omitempty"`
+	ContentType      string  `json:"contentType,omitempty"`
 }
 
 type Param struct {
 	Name     string `json:"name"`
 	Required bool   `json:"required"`
+	// Property is the input property holding the value, when it differs from the parameter name
+	Property string `json:"property,omitempty"`
+	// Prefixed marks parameters mapped through a name prefix, whose value may also be sent
+	// under the parameter name as the gateway does
+	Prefixed bool `json:"prefixed,omitempty"`
+	// Style and Explode control the serialization of array and object values as in OpenAPI 3
+	Style   string `json:"style,omitempty"`
+	Explode *bool  `json:"explode,omitempty"`
 }
 
 type MCPInputSchema struct {
@@ -59,6 +161,7 @@ type MCPInputSchema struct {
 
 type XMLElement struct {
 	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
 	Content  string       `xml:",chardata"`
 	Children []XMLElement `xml:",any"`
 }
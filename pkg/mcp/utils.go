@@ -1,52 +1,32 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
-	"encoding/xml"
-	"fmt"
+	"slices"
+	"strings"
 )
 
+// processJsonResponse compacts a JSON response of any JSON value.
+// Numbers and the order of keys are kept as received. Empty responses are returned as is.
 func processJsonResponse(inputString string) (string, error) {
-	var data map[string]any
-
-	err := json.Unmarshal([]byte(inputString), &data)
-	if err != nil {
-		logger.Error("Failed to unmarshal JSON", "error", err)
-		return "", err
+	if strings.TrimSpace(inputString) == "" {
+		return "", nil
 	}
-
-	compactJSONBytes, err := json.Marshal(data)
-	if err != nil {
-		logger.Error("Error marshalling data back to JSON", "error", err)
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(inputString)); err != nil {
+		logger.Error("Failed to compact JSON", "error", err)
 		return "", err
 	}
-
-	return string(compactJSONBytes), nil
+	return compacted.String(), nil
 }
 
-func mapToXMLElements(m map[string]any) []XMLElement {
-	elements := []XMLElement{}
-	for k, v := range m {
-		elem := XMLElement{XMLName: xml.Name{Local: k}}
-
-		switch val := v.(type) {
-		case string:
-			elem.Content = val
-		case map[string]any:
-			elem.Children = mapToXMLElements(val)
-		case []any:
-			for _, item := range val {
-				if itemMap, ok := item.(map[string]any); ok {
-					elem.Children = append(elem.Children, mapToXMLElements(itemMap)...)
-				} else {
-					elem.Children = append(elem.Children, XMLElement{XMLName: xml.Name{Local: "item"}, Content: fmt.Sprint(item)})
-				}
-			}
-		default:
-			elem.Content = fmt.Sprint(val)
-		}
-
-		elements = append(elements, elem)
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-	return elements
+	slices.Sort(keys)
+	return keys
 }
@@ -0,0 +1,711 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError describes a value that does not conform to its JSON schema.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ArgumentsValidationError is returned when the tool arguments do not conform to the input schema.
+type ArgumentsValidationError struct {
+	Errors []ValidationError
+}
+
+func (e *ArgumentsValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, validationErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", validationErr.Path, validationErr.Message))
+	}
+	return "invalid arguments: " + strings.Join(messages, "; ")
+}
+
+// OutputValidationError is returned when the response does not conform to the output schema.
+type OutputValidationError struct {
+	Errors []ValidationError
+}
+
+func (e *OutputValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, validationErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", validationErr.Path, validationErr.Message))
+	}
+	return "response does not match the output schema: " + strings.Join(messages, "; ")
+}
+
+// prefixes of input properties that the gateway sends without the prefix
+var parameterPrefixes = []string{"path_", "query_", "header_", "cookie_"}
+
+const maxSchemaDepth = 64
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+)
+
+// validateArguments validates the arguments of the request against the input schema.
+// Returns an ArgumentsValidationError listing every violation.
+func validateArguments(mcpRequest *MCPRequest, args map[string]any) error {
+	if mcpRequest.Schema == "" {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(mcpRequest.Schema), &schema); err != nil {
+		logger.Error("Error processing the MCP input schema", "error", err)
+		return err
+	}
+	properties, _ := schema["properties"].(map[string]any)
+	errors := validateSchema(schema, normalizeArgs(properties, args), "arguments", mcpRequest.patterns)
+	if len(errors) > 0 {
+		return &ArgumentsValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// validateOutput validates the processed response of the request against the output schema.
+// Returns an OutputValidationError listing every violation.
+func validateOutput(mcpRequest *MCPRequest, response string) error {
+	if mcpRequest.OutputSchema == "" {
+		return nil
+	}
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(mcpRequest.OutputSchema), &schema); err != nil {
+		logger.Error("Error processing the MCP output schema", "error", err)
+		return err
+	}
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(response))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return &OutputValidationError{Errors: []ValidationError{{Path: "response", Message: "is not a JSON value"}}}
+	}
+	errors := validateSchema(schema, value, "response", mcpRequest.patterns)
+	if len(errors) > 0 {
+		return &OutputValidationError{Errors: errors}
+	}
+	return nil
+}
+
+// normalizeArgs returns a copy of the arguments keyed by the input property names.
+// Arguments of prefixed properties sent without the prefix are moved to the prefixed name.
+func normalizeArgs(properties map[string]any, args map[string]any) map[string]any {
+	normalized := make(map[string]any, len(args))
+	for k, v := range args {
+		normalized[k] = v
+	}
+	for name := range properties {
+		if _, exists := normalized[name]; exists {
+			continue
+		}
+		for _, prefix := range parameterPrefixes {
+			refinedName, found := strings.CutPrefix(name, prefix)
+			if !found {
+				continue
+			}
+			if value, exists := args[refinedName]; exists {
+				normalized[name] = value
+				if _, isProperty := properties[refinedName]; !isProperty {
+					delete(normalized, refinedName)
+				}
+			}
+		}
+	}
+	return normalized
+}
+
+// ValidateSchema validates a decoded JSON value against a JSON schema.
+// Supports a subset of draft 2020-12 covering types, enums, string, number, array and object
+// constraints, common formats, combinators and local references.
+func ValidateSchema(schema any, value any, path string) []ValidationError {
+	return validateSchema(schema, value, path, nil)
+}
+
+// validateSchema validates a decoded JSON value against a JSON schema, using the precompiled
+// patterns of the schema when they are available.
+func validateSchema(schema any, value any, path string, patterns schemaPatterns) []ValidationError {
+	root, _ := schema.(map[string]any)
+	v := &schemaValidator{root: root, patterns: patterns}
+	v.validate(schema, value, path, 0)
+	return v.errors
+}
+
+type schemaValidator struct {
+	root     map[string]any
+	patterns schemaPatterns
+	errors   []ValidationError
+}
+
+// schemaPatterns are the compiled regular expressions of the pattern and patternProperties
+// keywords of a schema, keyed by their source.
+type schemaPatterns map[string]*regexp.Regexp
+
+// compileSchemaPatterns compiles the patterns of the given schemas so that validating a value
+// does not compile them again. Returns an error for the first invalid pattern.
+func compileSchemaPatterns(schemas ...json.RawMessage) (schemaPatterns, error) {
+	patterns := schemaPatterns{}
+	for _, data := range schemas {
+		if len(data) == 0 {
+			continue
+		}
+		var schema any
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, err
+		}
+		if err := patterns.collect(schema, 0); err != nil {
+			return nil, err
+		}
+	}
+	return patterns, nil
+}
+
+// collect compiles the patterns of a schema and of its subschemas.
+func (patterns schemaPatterns) collect(schema any, depth int) error {
+	s, ok := schema.(map[string]any)
+	if !ok || depth > maxSchemaDepth {
+		return nil
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		if err := patterns.add(pattern); err != nil {
+			return err
+		}
+	}
+	if patternProperties, ok := s["patternProperties"].(map[string]any); ok {
+		for pattern := range patternProperties {
+			if err := patterns.add(pattern); err != nil {
+				return err
+			}
+		}
+	}
+	var subschemas []any
+	for _, keyword := range []string{"properties", "patternProperties", "$defs", "definitions"} {
+		if schemas, ok := s[keyword].(map[string]any); ok {
+			for _, name := range sortedKeys(schemas) {
+				subschemas = append(subschemas, schemas[name])
+			}
+		}
+	}
+	for _, keyword := range []string{"prefixItems", "allOf", "anyOf", "oneOf"} {
+		if schemas, ok := s[keyword].([]any); ok {
+			subschemas = append(subschemas, schemas...)
+		}
+	}
+	for _, keyword := range []string{"items", "contains", "additionalProperties", "propertyNames", "not", "if", "then", "else"} {
+		if subschema, ok := s[keyword]; ok {
+			subschemas = append(subschemas, subschema)
+		}
+	}
+	for _, subschema := range subschemas {
+		if err := patterns.collect(subschema, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (patterns schemaPatterns) add(pattern string) error {
+	if _, exists := patterns[pattern]; exists {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %s: %v", pattern, err)
+	}
+	patterns[pattern] = re
+	return nil
+}
+
+// pattern returns the compiled pattern, compiling it when the schema was not precompiled.
+func (v *schemaValidator) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+func (v *schemaValidator) addError(path string, format string, a ...any) {
+	v.errors = append(v.errors, ValidationError{Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+// matches reports whether the value conforms to the schema without recording errors.
+func (v *schemaValidator) matches(schema any, value any, path string, depth int) bool {
+	child := &schemaValidator{root: v.root, patterns: v.patterns}
+	child.validate(schema, value, path, depth)
+	return len(child.errors) == 0
+}
+
+func (v *schemaValidator) validate(schema any, value any, path string, depth int) {
+	if depth > maxSchemaDepth {
+		v.addError(path, "schema is nested too deeply")
+		return
+	}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.addError(path, "no value is allowed")
+		}
+		return
+	case map[string]any:
+		v.validateKeywords(s, value, path, depth)
+	}
+}
+
+func (v *schemaValidator) validateKeywords(schema map[string]any, value any, path string, depth int) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			v.addError(path, "%s", err.Error())
+		} else {
+			v.validate(target, value, path, depth+1)
+		}
+	}
+
+	if types, ok := schema["type"]; ok && !matchesAnyType(value, types) {
+		v.addError(path, "expected type %s but got %s", formatTypes(types), jsonType(value))
+		// The remaining keywords assume the type is correct
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		v.addError(path, "must be one of %s", formatValues(enum))
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		v.addError(path, "must be equal to %s", formatValue(constant))
+	}
+
+	switch val := value.(type) {
+	case string:
+		v.validateString(schema, val, path)
+	case []any:
+		v.validateArray(schema, val, path, depth)
+	case map[string]any:
+		v.validateObject(schema, val, path, depth)
+	default:
+		if number, ok := toFloat(value); ok {
+			v.validateNumber(schema, number, path)
+		}
+	}
+
+	v.validateCombinators(schema, value, path, depth)
+}
+
+func (v *schemaValidator) validateString(schema map[string]any, value string, path string) {
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := toFloat(schema["minLength"]); ok && float64(length) < minLength {
+		v.addError(path, "must be at least %v characters long", minLength)
+	}
+	if maxLength, ok := toFloat(schema["maxLength"]); ok && float64(length) > maxLength {
+		v.addError(path, "must be at most %v characters long", maxLength)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := v.pattern(pattern)
+		if err != nil {
+			logger.Warn("Invalid pattern in schema", "pattern", pattern, "error", err)
+		} else if !re.MatchString(value) {
+			v.addError(path, "must match pattern %s", pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && !matchesFormat(format, value) {
+		v.addError(path, "must be a valid %s", format)
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]any, value float64, path string) {
+	if minimum, ok := toFloat(schema["minimum"]); ok {
+		// OpenAPI 3.0 schemas declare exclusive bounds as booleans next to the bound
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && value <= minimum {
+			v.addError(path, "must be greater than %v", minimum)
+		} else if value < minimum {
+			v.addError(path, "must be greater than or equal to %v", minimum)
+		}
+	}
+	if maximum, ok := toFloat(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && value >= maximum {
+			v.addError(path, "must be less than %v", maximum)
+		} else if value > maximum {
+			v.addError(path, "must be less than or equal to %v", maximum)
+		}
+	}
+	if exclusiveMinimum, ok := toFloat(schema["exclusiveMinimum"]); ok && value <= exclusiveMinimum {
+		v.addError(path, "must be greater than %v", exclusiveMinimum)
+	}
+	if exclusiveMaximum, ok := toFloat(schema["exclusiveMaximum"]); ok && value >= exclusiveMaximum {
+		v.addError(path, "must be less than %v", exclusiveMaximum)
+	}
+	if multipleOf, ok := toFloat(schema["multipleOf"]); ok && multipleOf > 0 {
+		quotient := value / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.addError(path, "must be a multiple of %v", multipleOf)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]any, value []any, path string, depth int) {
+	if minItems, ok := toFloat(schema["minItems"]); ok && float64(len(value)) < minItems {
+		v.addError(path, "must have at least %v items", minItems)
+	}
+	if maxItems, ok := toFloat(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+		v.addError(path, "must have at most %v items", maxItems)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 0; i < len(value); i++ {
+			for j := i + 1; j < len(value); j++ {
+				if jsonEqual(value[i], value[j]) {
+					v.addError(path, "items at index %d and %d must be unique", i, j)
+				}
+			}
+		}
+	}
+
+	prefixItems, _ := schema["prefixItems"].([]any)
+	for i, item := range value {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefixItems) {
+			v.validate(prefixItems[i], item, itemPath, depth+1)
+		} else if items, ok := schema["items"]; ok {
+			v.validate(items, item, itemPath, depth+1)
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		count := 0
+		for i, item := range value {
+			if v.matches(contains, item, fmt.Sprintf("%s[%d]", path, i), depth+1) {
+				count++
+			}
+		}
+		minContains := 1.0
+		if m, ok := toFloat(schema["minContains"]); ok {
+			minContains = m
+		}
+		if float64(count) < minContains {
+			v.addError(path, "must contain at least %v matching items", minContains)
+		}
+		if maxContains, ok := toFloat(schema["maxContains"]); ok && float64(count) > maxContains {
+			v.addError(path, "must contain at most %v matching items", maxContains)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]any, value map[string]any, path string, depth int) {
+	if minProperties, ok := toFloat(schema["minProperties"]); ok && float64(len(value)) < minProperties {
+		v.addError(path, "must have at least %v properties", minProperties)
+	}
+	if maxProperties, ok := toFloat(schema["maxProperties"]); ok && float64(len(value)) > maxProperties {
+		v.addError(path, "must have at most %v properties", maxProperties)
+	}
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, exists := value[name]; !exists {
+				v.addError(propertyPath(path, name), "is required")
+			}
+		}
+	}
+	if dependentRequired, ok := schema["dependentRequired"].(map[string]any); ok {
+		for name, dependencies := range dependentRequired {
+			if _, exists := value[name]; !exists {
+				continue
+			}
+			list, _ := dependencies.([]any)
+			for _, d := range list {
+				dependency, _ := d.(string)
+				if _, exists := value[dependency]; !exists {
+					v.addError(propertyPath(path, dependency), "is required when %s is present", name)
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	patternProperties, _ := schema["patternProperties"].(map[string]any)
+	additionalProperties, hasAdditional := schema["additionalProperties"]
+	propertyNames, hasPropertyNames := schema["propertyNames"]
+	for _, name := range sortedKeys(value) {
+		propValue := value[name]
+		propPath := propertyPath(path, name)
+		if hasPropertyNames {
+			v.validate(propertyNames, name, propPath, depth+1)
+		}
+		matched := false
+		if propSchema, ok := properties[name]; ok {
+			matched = true
+			v.validate(propSchema, propValue, propPath, depth+1)
+		}
+		for pattern, propSchema := range patternProperties {
+			re, err := v.pattern(pattern)
+			if err != nil || !re.MatchString(name) {
+				continue
+			}
+			matched = true
+			v.validate(propSchema, propValue, propPath, depth+1)
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additionalProperties.(bool); ok && !allowed {
+			v.addError(propPath, "is not an allowed property")
+		} else {
+			v.validate(additionalProperties, propValue, propPath, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateCombinators(schema map[string]any, value any, path string, depth int) {
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			v.validate(sub, value, path, depth+1)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.matches(sub, value, path, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.addError(path, "must match at least one of the anyOf schemas")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		count := 0
+		for _, sub := range oneOf {
+			if v.matches(sub, value, path, depth+1) {
+				count++
+			}
+		}
+		if count != 1 {
+			v.addError(path, "must match exactly one of the oneOf schemas but matched %d", count)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.matches(not, value, path, depth+1) {
+		v.addError(path, "must not match the schema in not")
+	}
+	if ifSchema, ok := schema["if"]; ok {
+		if v.matches(ifSchema, value, path, depth+1) {
+			if then, ok := schema["then"]; ok {
+				v.validate(then, value, path, depth+1)
+			}
+		} else if elseSchema, ok := schema["else"]; ok {
+			v.validate(elseSchema, value, path, depth+1)
+		}
+	}
+}
+
+// resolveRef resolves a reference local to the root schema such as #/$defs/address.
+func (v *schemaValidator) resolveRef(ref string) (any, error) {
+	pointer, found := strings.CutPrefix(ref, "#")
+	if !found {
+		return nil, fmt.Errorf("unsupported schema reference %s", ref)
+	}
+	var current any = v.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %s", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %s", ref)
+		}
+	}
+	return current, nil
+}
+
+func matchesFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "time":
+		_, err := time.Parse(time.RFC3339, "1970-01-01T"+value)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	case "uri-reference":
+		_, err := url.Parse(value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		return net.ParseIP(value) != nil && strings.Contains(value, ":")
+	case "hostname":
+		return len(value) <= 253 && hostnamePattern.MatchString(value)
+	default:
+		// Unknown formats are annotations only
+		return true
+	}
+}
+
+func matchesAnyType(value any, types any) bool {
+	switch t := types.(type) {
+	case string:
+		return matchesType(value, t)
+	case []any:
+		for _, item := range t {
+			if name, ok := item.(string); ok && matchesType(value, name) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesType(value any, typeName string) bool {
+	actual := jsonType(value)
+	switch typeName {
+	case "number":
+		return actual == "number" || actual == "integer"
+	default:
+		return actual == typeName
+	}
+}
+
+// jsonType returns the JSON schema type of a decoded JSON value.
+// Whole numbers are reported as integer.
+func jsonType(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		if number, ok := toFloat(val); ok {
+			if number == math.Trunc(number) && !math.IsInf(number, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return reflect.TypeOf(value).String()
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, comparing numbers by value.
+func jsonEqual(a any, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, exists := y[k]
+			if !exists || !jsonEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if jsonEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTypes(types any) string {
+	if list, ok := types.([]any); ok {
+		names := make([]string, 0, len(list))
+		for _, t := range list {
+			names = append(names, fmt.Sprint(t))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func formatValue(value any) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(formatted)
+}
+
+func formatValues(values []any) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, formatValue(value))
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+func propertyPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
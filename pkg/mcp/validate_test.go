@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+const testInputSchema = `{
+	"type": "object",
+	"required": ["query_input1", "header_input2"],
+	"contentType": "application/json",
+	"properties": {
+		"query_input1": {"type": "string", "enum": ["a", "b"]},
+		"header_input2": {"type": "string", "format": "uuid"},
+		"query_limit": {"type": "integer", "minimum": 1, "maximum": 100},
+		"requestBody": {
+			"type": "object",
+			"required": ["address", "pizzaType", "quantity"],
+			"properties": {
+				"address": {"type": "string", "minLength": 5},
+				"pizzaType": {"$ref": "#/$defs/pizzaType"},
+				"quantity": {"type": "number", "exclusiveMinimum": 0},
+				"email": {"type": "string", "format": "email"},
+				"toppings": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true}
+			},
+			"additionalProperties": false
+		}
+	},
+	"$defs": {
+		"pizzaType": {"type": "string", "pattern": "^[A-Z][a-z]+$"}
+	}
+}`
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name       string
+		arguments  string
+		wantErrors []ValidationError
+	}{
+		{
+			name:      "valid prefixed arguments",
+			arguments: `{"query_input1":"a","header_input2":"4b2f0b5e-8d4c-4a5e-9a9e-7c4f1b2a3d4e","requestBody":{"address":"1 Main St","pizzaType":"Margherita","quantity":2}}`,
+		},
+		{
+			name:      "valid arguments without prefixes",
+			arguments: `{"input1":"b","input2":"4b2f0b5e-8d4c-4a5e-9a9e-7c4f1b2a3d4e","limit":10}`,
+		},
+		{
+			name:      "missing required parameters",
+			arguments: `{"input1":"a"}`,
+			wantErrors: []ValidationError{
+				{Path: "arguments.header_input2", Message: "is required"},
+			},
+		},
+		{
+			name:      "invalid parameter values",
+			arguments: `{"input1":"c","input2":"not-a-uuid","limit":1.5}`,
+			wantErrors: []ValidationError{
+				{Path: "arguments.header_input2", Message: "must be a valid uuid"},
+				{Path: "arguments.query_input1", Message: `must be one of ["a", "b"]`},
+				{Path: "arguments.query_limit", Message: "expected type integer but got number"},
+			},
+		},
+		{
+			name:      "invalid request body",
+			arguments: `{"input1":"a","input2":"4b2f0b5e-8d4c-4a5e-9a9e-7c4f1b2a3d4e","requestBody":{"address":"1","pizzaType":"margherita","quantity":0,"email":"nope","toppings":["x","x","y"],"extra":true}}`,
+			wantErrors: []ValidationError{
+				{Path: "arguments.requestBody.address", Message: "must be at least 5 characters long"},
+				{Path: "arguments.requestBody.email", Message: "must be a valid email"},
+				{Path: "arguments.requestBody.extra", Message: "is not an allowed property"},
+				{Path: "arguments.requestBody.pizzaType", Message: "must match pattern ^[A-Z][a-z]+$"},
+				{Path: "arguments.requestBody.quantity", Message: "must be greater than 0"},
+				{Path: "arguments.requestBody.toppings", Message: "must have at most 2 items"},
+				{Path: "arguments.requestBody.toppings", Message: "items at index 0 and 1 must be unique"},
+			},
+		},
+		{
+			name:      "missing nested required fields",
+			arguments: `{"input1":"a","input2":"4b2f0b5e-8d4c-4a5e-9a9e-7c4f1b2a3d4e","requestBody":{"address":"1 Main St"}}`,
+			wantErrors: []ValidationError{
+				{Path: "arguments.requestBody.pizzaType", Message: "is required"},
+				{Path: "arguments.requestBody.quantity", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpRequest := &MCPRequest{Arguments: tt.arguments, Schema: testInputSchema}
+			args, err := parseArgs(mcpRequest)
+			if err != nil {
+				t.Fatalf("parseArgs() error = %v", err)
+			}
+			err = validateArguments(mcpRequest, args)
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Errorf("validateArguments() error = %v, want nil", err)
+				}
+				return
+			}
+			validationErr, ok := err.(*ArgumentsValidationError)
+			if !ok {
+				t.Fatalf("validateArguments() error = %v, want ArgumentsValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Errors, tt.wantErrors) {
+				got, _ := json.MarshalIndent(validationErr.Errors, "", "  ")
+				t.Errorf("validateArguments() errors = %s", got)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaCombinators(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		value   string
+		wantErr bool
+	}{
+		{"oneOf matches one", `{"oneOf":[{"type":"string"},{"type":"integer"}]}`, `"a"`, false},
+		{"oneOf matches both", `{"oneOf":[{"type":"number"},{"type":"integer"}]}`, `1`, true},
+		{"anyOf matches none", `{"anyOf":[{"type":"string"},{"type":"boolean"}]}`, `1`, true},
+		{"allOf", `{"allOf":[{"minimum":1},{"maximum":5}]}`, `6`, true},
+		{"not", `{"not":{"type":"null"}}`, `null`, true},
+		{"if then", `{"if":{"properties":{"kind":{"const":"card"}}},"then":{"required":["number"]}}`, `{"kind":"card"}`, true},
+		{"nullable type list", `{"type":["string","null"]}`, `null`, false},
+		{"date-time format", `{"type":"string","format":"date-time"}`, `"2024-01-02T03:04:05Z"`, false},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schema, value any
+			if err := json.Unmarshal([]byte(tt.schema), &schema); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			errors := ValidateSchema(schema, value, "value")
+			if (len(errors) > 0) != tt.wantErr {
+				t.Errorf("ValidateSchema() errors = %v, wantErr %v", errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileSchemaPatterns(t *testing.T) {
+	tests := []struct {
+		name         string
+		schemas      []string
+		wantPatterns []string
+		wantErr      bool
+	}{
+		{
+			name:         "nested patterns",
+			schemas:      []string{testInputSchema, `{"type":"object","patternProperties":{"^x-":{"type":"string","pattern":"^v[0-9]+$"}},"additionalProperties":{"anyOf":[{"pattern":"^a"}]}}`},
+			wantPatterns: []string{"^[A-Z][a-z]+$", "^a", "^v[0-9]+$", "^x-"},
+		},
+		{
+			name:    "property named pattern",
+			schemas: []string{`{"type":"object","properties":{"pattern":{"type":"string"}}}`},
+		},
+		{
+			name:    "invalid pattern",
+			schemas: []string{`{"type":"object","properties":{"id":{"items":{"pattern":"[a-z"}}}}`},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern property",
+			schemas: []string{`{"type":"object","$defs":{"tags":{"patternProperties":{"(":{}}}}}`},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var schemas []json.RawMessage
+			for _, schema := range tt.schemas {
+				schemas = append(schemas, json.RawMessage(schema))
+			}
+			patterns, err := compileSchemaPatterns(schemas...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compileSchemaPatterns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for pattern := range patterns {
+				got = append(got, pattern)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.wantPatterns) {
+				t.Errorf("compileSchemaPatterns() = %v, want %v", got, tt.wantPatterns)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"slices"
+)
+
+// DefaultXMLRoot is the root element of XML bodies whose schema does not declare a name.
+const DefaultXMLRoot = "Body"
+
+// XMLObject is the XML metadata of a schema as defined by the OpenAPI XML object.
+type XMLObject struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	Attribute bool   `json:"attribute,omitempty"`
+	Wrapped   bool   `json:"wrapped,omitempty"`
+}
+
+// xmlSchema is the part of a JSON schema that controls the XML serialization of a value.
+type xmlSchema struct {
+	XML        XMLObject
+	Properties *orderedSchemas
+	Items      *xmlSchema
+}
+
+func (s *xmlSchema) UnmarshalJSON(data []byte) error {
+	// Boolean schemas carry no XML metadata
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil
+	}
+	var raw struct {
+		XML        *XMLObject      `json:"xml"`
+		Properties *orderedSchemas `json:"properties"`
+		Items      json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.XML != nil {
+		s.XML = *raw.XML
+	}
+	s.Properties = raw.Properties
+	if bytes.HasPrefix(bytes.TrimSpace(raw.Items), []byte("{")) {
+		s.Items = &xmlSchema{}
+		if err := json.Unmarshal(raw.Items, s.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *xmlSchema) meta() XMLObject {
+	if s == nil {
+		return XMLObject{}
+	}
+	return s.XML
+}
+
+func (s *xmlSchema) property(name string) *xmlSchema {
+	if s == nil || s.Properties == nil {
+		return nil
+	}
+	return s.Properties.schemas[name]
+}
+
+func (s *xmlSchema) items() *xmlSchema {
+	if s == nil {
+		return nil
+	}
+	return s.Items
+}
+
+// propertyOrder returns the keys of the value in the order of the schema properties, followed by
+// the keys the schema does not declare in sorted order.
+func (s *xmlSchema) propertyOrder(value map[string]any) []string {
+	keys := make([]string, 0, len(value))
+	if s != nil && s.Properties != nil {
+		for _, k := range s.Properties.keys {
+			if _, exists := value[k]; exists {
+				keys = append(keys, k)
+			}
+		}
+	}
+	for _, k := range sortedKeys(value) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// orderedSchemas holds the property schemas of an object schema in document order.
+type orderedSchemas struct {
+	keys    []string
+	schemas map[string]*xmlSchema
+}
+
+func (o *orderedSchemas) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("properties must be an object")
+	}
+	o.schemas = make(map[string]*xmlSchema)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		schema := &xmlSchema{}
+		if err := decoder.Decode(schema); err != nil {
+			return err
+		}
+		if _, exists := o.schemas[key]; !exists {
+			o.keys = append(o.keys, key)
+		}
+		o.schemas[key] = schema
+	}
+	return nil
+}
+
+// parseXMLBodySchema returns the schema of the body property of an input schema.
+// Returns nil if the input schema does not describe the body property.
+func parseXMLBodySchema(inputSchema string, bodyProperty string) (*xmlSchema, error) {
+	if inputSchema == "" {
+		return nil, nil
+	}
+	schema := &xmlSchema{}
+	if err := json.Unmarshal([]byte(inputSchema), schema); err != nil {
+		return nil, err
+	}
+	return schema.property(bodyProperty), nil
+}
+
+// buildXMLBody converts an object to an XML document following the XML metadata of its schema.
+// The root element is named by the schema, defaulting to DefaultXMLRoot.
+func buildXMLBody(schema *xmlSchema, value map[string]any) XMLElement {
+	return objectElement(DefaultXMLRoot, schema, value)
+}
+
+func objectElement(name string, schema *xmlSchema, value map[string]any) XMLElement {
+	meta := schema.meta()
+	elem := XMLElement{XMLName: xmlElementName(meta, name), Attrs: namespaceAttrs(meta)}
+	for _, k := range schema.propertyOrder(value) {
+		v := value[k]
+		if v == nil {
+			continue
+		}
+		propertySchema := schema.property(k)
+		if propertyMeta := propertySchema.meta(); propertyMeta.Attribute && isPrimitive(v) {
+			elem.Attrs = append(elem.Attrs, xml.Attr{Name: xmlElementName(propertyMeta, k), Value: formatParamValue(v)})
+			continue
+		}
+		elem.Children = append(elem.Children, valueElements(k, propertySchema, v)...)
+	}
+	return elem
+}
+
+// valueElements converts a value to the XML elements representing it.
+// Arrays are represented by an element per item, inside a wrapping element if the schema is wrapped.
+func valueElements(name string, schema *xmlSchema, value any) []XMLElement {
+	meta := schema.meta()
+	switch val := value.(type) {
+	case []any:
+		itemSchema := schema.items()
+		itemName := name
+		if itemMeta := itemSchema.meta(); itemMeta.Name != "" {
+			itemName = itemMeta.Name
+		}
+		var items []XMLElement
+		for _, item := range val {
+			if item != nil {
+				items = append(items, valueElements(itemName, itemSchema, item)...)
+			}
+		}
+		if meta.Wrapped {
+			return []XMLElement{{XMLName: xmlElementName(meta, name), Attrs: namespaceAttrs(meta), Children: items}}
+		}
+		return items
+	case map[string]any:
+		return []XMLElement{objectElement(name, schema, val)}
+	default:
+		return []XMLElement{{XMLName: xmlElementName(meta, name), Attrs: namespaceAttrs(meta), Content: formatParamValue(val)}}
+	}
+}
+
+// xmlElementName returns the name declared by the XML metadata, qualified by its prefix.
+func xmlElementName(meta XMLObject, name string) xml.Name {
+	if meta.Name != "" {
+		name = meta.Name
+	}
+	if meta.Prefix != "" {
+		name = meta.Prefix + ":" + name
+	}
+	return xml.Name{Local: name}
+}
+
+// namespaceAttrs returns the namespace declaration of the XML metadata.
+func namespaceAttrs(meta XMLObject) []xml.Attr {
+	if meta.Namespace == "" || meta.Attribute {
+		return nil
+	}
+	name := "xmlns"
+	if meta.Prefix != "" {
+		name += ":" + meta.Prefix
+	}
+	return []xml.Attr{{Name: xml.Name{Local: name}, Value: meta.Namespace}}
+}
+
+func isPrimitive(value any) bool {
+	switch value.(type) {
+	case []any, map[string]any:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestBuildXMLBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		body   map[string]any
+		want   string
+	}{
+		{
+			name:   "no schema",
+			schema: "",
+			body:   map[string]any{"b": "2", "a": float64(1), "tags": []any{"x", "y"}},
+			want:   `<Body><a>1</a><b>2</b><tags>x</tags><tags>y</tags></Body>`,
+		},
+		{
+			name: "schema order and root name",
+			schema: `{"type":"object","properties":{"requestBody":{"type":"object","xml":{"name":"Order"},"properties":{
+				"item":{"type":"string"},
+				"id":{"type":"integer","xml":{"attribute":true}},
+				"quantity":{"type":"integer"}
+			}}}}`,
+			body: map[string]any{"quantity": float64(2), "id": float64(7), "item": "book", "note": "gift"},
+			want: `<Order id="7"><item>book</item><quantity>2</quantity><note>gift</note></Order>`,
+		},
+		{
+			name: "namespaces and wrapped arrays",
+			schema: `{"type":"object","properties":{"requestBody":{"type":"object","xml":{"name":"Envelope","prefix":"soap","namespace":"http://schemas.xmlsoap.org/soap/envelope/"},"properties":{
+				"lines":{"type":"array","xml":{"wrapped":true,"name":"Lines"},"items":{"type":"string","xml":{"name":"Line"}}},
+				"codes":{"type":"array","items":{"type":"string","xml":{"name":"Code","namespace":"urn:codes"}}},
+				"lang":{"type":"string","xml":{"attribute":true,"prefix":"xml"}}
+			}}}}`,
+			body: map[string]any{"lines": []any{"a", "b"}, "codes": []any{"c"}, "lang": "en"},
+			want: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xml:lang="en"><Lines><Line>a</Line><Line>b</Line></Lines><Code xmlns="urn:codes">c</Code></soap:Envelope>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parseXMLBodySchema(tt.schema, "requestBody")
+			if err != nil {
+				t.Fatalf("parseXMLBodySchema() error = %v", err)
+			}
+			got, err := xml.Marshal(buildXMLBody(schema, tt.body))
+			if err != nil {
+				t.Fatalf("xml.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("buildXMLBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	mcp "mcp-server/pkg/mcp"
+	"mcp-server/pkg/service"
+)
+
+var logger = service.GetLogger()
+
+// Options controls how tools are generated from an OpenAPI document.
+type Options struct {
+	// Endpoint overrides the server URL of the document.
+	Endpoint string
+	// NaturalNames names input properties after their parameters and declares the location with
+	// the x-mcp-in extension instead of a name prefix, unless parameter names collide.
+	NaturalNames bool
+}
+
+// methods supported by the transformation, in the order operations are generated
+var methods = []string{"get", "put", "post", "delete", "options", "patch"}
+
+// request body content types supported by the transformation, in order of preference
+var supportedContentTypes = []string{mcp.ContentTypeJSON, mcp.ContentTypeXML, mcp.ContentTypeForm, mcp.ContentTypeMultipart}
+
+// header parameters that are ignored as required by the OpenAPI specification
+var ignoredHeaders = []string{"accept", "content-type", "authorization"}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+type generator struct {
+	root         *object
+	endpoint     string
+	naturalNames bool
+	// names are the tool names already generated
+	names map[string]bool
+}
+
+// Generate creates a tool definition for every operation of an OpenAPI 3 document in YAML or JSON.
+// Parameters are mapped to input properties using the path_, query_, header_ and cookie_ prefixes and the
+// request body to the requestBody property.
+func Generate(data []byte, opts Options) ([]*mcp.ToolDefinition, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %v", err)
+	}
+	doc, err := fromYAML(&node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %v", err)
+	}
+	root, ok := doc.(*object)
+	if !ok {
+		return nil, fmt.Errorf("OpenAPI document must be an object")
+	}
+	if !strings.HasPrefix(root.String("openapi"), "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 documents are supported")
+	}
+
+	g := &generator{root: root, naturalNames: opts.NaturalNames, names: make(map[string]bool)}
+	g.endpoint = opts.Endpoint
+	if g.endpoint == "" {
+		g.endpoint = serverURL(root)
+	}
+	if endpoint, err := url.Parse(g.endpoint); err != nil || !endpoint.IsAbs() {
+		return nil, fmt.Errorf("an absolute endpoint is required, found %q", g.endpoint)
+	}
+
+	var tools []*mcp.ToolDefinition
+	paths := root.Object("paths")
+	for _, path := range paths.Keys() {
+		item, ok := g.resolve(paths.Get(path)).(*object)
+		if !ok {
+			continue
+		}
+		for _, method := range methods {
+			operation := item.Object(method)
+			if operation == nil {
+				continue
+			}
+			tool, err := g.operationTool(path, method, item, operation)
+			if err != nil {
+				logger.Warn("Skipping operation", "path", path, "method", method, "error", err)
+				continue
+			}
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+func (g *generator) operationTool(path string, method string, item *object, operation *object) (*mcp.ToolDefinition, error) {
+	properties := newObject()
+	var required []any
+
+	params := g.parameters(item, operation)
+	nameCounts := make(map[string]int)
+	for _, param := range params {
+		nameCounts[param.String("name")]++
+	}
+	for _, param := range params {
+		name := param.String("name")
+		in := param.String("in")
+		var key string
+		switch in {
+		case mcp.LocationPath:
+			key = "path_" + name
+		case mcp.LocationQuery:
+			key = "query_" + name
+		case mcp.LocationHeader:
+			if slices.Contains(ignoredHeaders, strings.ToLower(name)) {
+				continue
+			}
+			key = "header_" + name
+		case mcp.LocationCookie:
+			key = "cookie_" + name
+		default:
+			logger.Warn("Skipping unsupported parameter location", "path", path, "parameter", name, "in", in)
+			continue
+		}
+		schema := g.parameterSchema(param)
+		if g.naturalNames && nameCounts[name] == 1 && name != "requestBody" {
+			key = name
+			schema.Set(mcp.ExtensionIn, in)
+		}
+		properties.Set(key, schema)
+		if in == mcp.LocationPath || param.Bool("required") {
+			required = append(required, key)
+		}
+	}
+
+	contentType := ""
+	if requestBody, ok := g.resolve(operation.Get("requestBody")).(*object); ok {
+		var schema *object
+		var err error
+		contentType, schema, err = g.requestBodySchema(requestBody)
+		if err != nil {
+			return nil, err
+		}
+		if contentType == mcp.ContentTypeXML {
+			g.setXMLRootName(path, method, schema)
+		}
+		properties.Set("requestBody", schema)
+		if requestBody.Bool("required") {
+			required = append(required, "requestBody")
+		}
+	}
+
+	inputSchema := newObject()
+	inputSchema.Set("type", "object")
+	if contentType != "" {
+		inputSchema.Set("contentType", contentType)
+	}
+	inputSchema.Set("properties", properties)
+	if len(required) > 0 {
+		inputSchema.Set("required", required)
+	}
+	schemaBytes, err := json.Marshal(inputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputSchema json.RawMessage
+	if schema := responseSchema(operation); schema != nil {
+		outputSchema, err = json.Marshal(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &mcp.ToolDefinition{
+		Name:         g.toolName(path, method, operation),
+		Description:  description(operation),
+		InputSchema:  schemaBytes,
+		OutputSchema: outputSchema,
+		Backend: &mcp.BackendInfo{
+			Endpoint: g.endpoint,
+			Target:   path,
+			Verb:     strings.ToUpper(method),
+		},
+	}, nil
+}
+
+// parameters merges the path level parameters with the operation parameters.
+// Operation parameters override path level parameters with the same name and location.
+func (g *generator) parameters(item *object, operation *object) []*object {
+	var params []*object
+	index := make(map[string]int)
+	for _, list := range []any{item.Get("parameters"), operation.Get("parameters")} {
+		items, _ := list.([]any)
+		for _, p := range items {
+			param, ok := g.resolve(p).(*object)
+			if !ok {
+				continue
+			}
+			key := param.String("in") + ":" + param.String("name")
+			if i, exists := index[key]; exists {
+				params[i] = param
+			} else {
+				index[key] = len(params)
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
+func (g *generator) parameterSchema(param *object) *object {
+	schema, ok := g.resolve(param.Get("schema")).(*object)
+	if !ok {
+		// Parameters may describe their schema through a single media type instead
+		content := param.Object("content")
+		if mediaTypes := content.Keys(); len(mediaTypes) > 0 {
+			schema, ok = g.resolve(content.Object(mediaTypes[0]).Get("schema")).(*object)
+		}
+	}
+	if !ok {
+		schema = newObject()
+		schema.Set("type", "string")
+	}
+	if desc := param.String("description"); desc != "" && schema.String("description") == "" {
+		schema.Set("description", desc)
+	}
+	// Keep the serialization of array and object values declared by the parameter
+	if style := param.String("style"); style != "" {
+		schema.Set("style", style)
+	}
+	if explode, ok := param.Get("explode").(bool); ok {
+		schema.Set("explode", explode)
+	}
+	return schema
+}
+
+func (g *generator) requestBodySchema(requestBody *object) (string, *object, error) {
+	content := requestBody.Object("content")
+	for _, contentType := range supportedContentTypes {
+		mediaType := content.Object(contentType)
+		if mediaType == nil {
+			continue
+		}
+		schema, ok := g.resolve(mediaType.Get("schema")).(*object)
+		if !ok {
+			schema = newObject()
+			schema.Set("type", "object")
+		}
+		if desc := requestBody.String("description"); desc != "" && schema.String("description") == "" {
+			schema.Set("description", desc)
+		}
+		if contentType == mcp.ContentTypeMultipart {
+			convertFileProperties(schema)
+		}
+		return contentType, schema, nil
+	}
+	return "", nil, fmt.Errorf("unsupported request body content types: %v", content.Keys())
+}
+
+// responseSchema returns the schema of the JSON object returned by the first success response of
+// the operation, or by the default response if there is no success response, which becomes the
+// output schema of the tool.
+// Returns nil if the operation does not return a JSON object.
+func responseSchema(operation *object) *object {
+	responses := operation.Object("responses")
+	response := responses.Object("default")
+	for _, code := range responses.Keys() {
+		if strings.HasPrefix(code, "2") {
+			response = responses.Object(code)
+			break
+		}
+	}
+	content := response.Object("content")
+	for _, contentType := range content.Keys() {
+		if !isJSONMediaType(contentType) {
+			continue
+		}
+		schema := content.Object(contentType).Object("schema")
+		if schema.String("type") == "object" {
+			return schema
+		}
+		return nil
+	}
+	return nil
+}
+
+// isJSONMediaType reports whether the media type of a response is application/json or a JSON
+// based type such as application/problem+json, ignoring its parameters.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == mcp.ContentTypeJSON || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// resolve returns a copy of the value with all local references replaced by the referenced values.
+func (g *generator) resolve(value any) any {
+	return g.resolveRefs(value, nil)
+}
+
+func (g *generator) resolveRefs(value any, seen []string) any {
+	switch v := value.(type) {
+	case *object:
+		if ref := v.String("$ref"); ref != "" {
+			if slices.Contains(seen, ref) {
+				// Circular references are cut off at the point they repeat
+				cut := newObject()
+				cut.Set("type", "object")
+				cut.Set("description", fmt.Sprintf("Circular reference to %s", ref))
+				return cut
+			}
+			target, err := g.lookup(ref)
+			if err != nil {
+				logger.Warn("Failed to resolve reference", "ref", ref, "error", err)
+				return newObject()
+			}
+			resolved := g.resolveRefs(target, append(slices.Clone(seen), ref))
+			// Keywords next to the reference override the referenced ones
+			if obj, ok := resolved.(*object); ok {
+				for _, key := range v.Keys() {
+					if key != "$ref" {
+						obj.Set(key, g.resolveRefs(v.Get(key), seen))
+					}
+				}
+			}
+			return resolved
+		}
+		resolved := newObject()
+		for _, key := range v.Keys() {
+			resolved.Set(key, g.resolveRefs(v.Get(key), seen))
+		}
+		convertNullable(resolved)
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolved[i] = g.resolveRefs(item, seen)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
+
+// lookup returns the value a local JSON pointer reference points to.
+func (g *generator) lookup(ref string) (any, error) {
+	pointer, found := strings.CutPrefix(ref, "#/")
+	if !found {
+		return nil, fmt.Errorf("only local references are supported")
+	}
+	var current any = g.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(*object)
+		if !ok || obj.Get(token) == nil {
+			return nil, fmt.Errorf("reference target not found")
+		}
+		current = obj.Get(token)
+	}
+	return current, nil
+}
+
+// convertNullable converts the OpenAPI 3.0 nullable keyword to a JSON Schema type list.
+func convertNullable(schema *object) {
+	if !schema.Bool("nullable") {
+		return
+	}
+	schema.Delete("nullable")
+	if schemaType := schema.String("type"); schemaType != "" {
+		schema.Set("type", []any{schemaType, "null"})
+	}
+}
+
+// setXMLRootName names the root element of an XML body after the referenced schema component,
+// as OpenAPI does when the schema does not declare a name.
+func (g *generator) setXMLRootName(path string, method string, schema *object) {
+	// The operation passed around is resolved, so the reference is read from the document
+	body := g.root.Object("paths").Object(path).Object(method).Object("requestBody")
+	if ref := body.String("$ref"); ref != "" {
+		target, err := g.lookup(ref)
+		if err != nil {
+			return
+		}
+		body, _ = target.(*object)
+	}
+	mediaType := body.Object("content").Object(mcp.ContentTypeXML)
+	name, found := strings.CutPrefix(mediaType.Object("schema").String("$ref"), "#/components/schemas/")
+	if !found || name == "" {
+		return
+	}
+	xmlObject := schema.Object("xml")
+	if xmlObject == nil {
+		xmlObject = newObject()
+		schema.Set("xml", xmlObject)
+	}
+	if xmlObject.String("name") == "" {
+		xmlObject.Set("name", name)
+	}
+}
+
+// convertFileProperties replaces the binary properties of a multipart body schema with the file
+// object the transformation expects, holding the base64 encoded content, file name and media type.
+func convertFileProperties(schema *object) {
+	properties := schema.Object("properties")
+	for _, name := range properties.Keys() {
+		property := properties.Object(name)
+		if isBinary(property) {
+			properties.Set(name, fileSchema(property.String("description")))
+		} else if items := property.Object("items"); property.String("type") == "array" && isBinary(items) {
+			property.Set("items", fileSchema(items.String("description")))
+		}
+	}
+}
+
+// isBinary reports whether a string schema is a file. Strings of the byte format are base64 in
+// JSON and stay plain strings.
+func isBinary(schema *object) bool {
+	return schema.String("type") == "string" &&
+		(schema.String("format") == "binary" || schema.String("contentEncoding") != "")
+}
+
+func fileSchema(desc string) *object {
+	content := newObject()
+	content.Set("type", "string")
+	content.Set("contentEncoding", "base64")
+	content.Set("description", "Base64 encoded file content")
+	filename := newObject()
+	filename.Set("type", "string")
+	contentType := newObject()
+	contentType.Set("type", "string")
+	contentType.Set("description", "Media type of the file")
+
+	properties := newObject()
+	properties.Set("content", content)
+	properties.Set("filename", filename)
+	properties.Set("contentType", contentType)
+	schema := newObject()
+	schema.Set("type", "object")
+	if desc != "" {
+		schema.Set("description", desc)
+	}
+	schema.Set("properties", properties)
+	schema.Set("required", []any{"content", "filename"})
+	return schema
+}
+
+// toolName returns a unique tool name made of the valid characters of the operation ID, or of the
+// method and path when the operation has no ID or none of its characters are valid.
+func (g *generator) toolName(path string, method string, operation *object) string {
+	name := sanitizeName(operation.String("operationId"))
+	if name == "" {
+		name = sanitizeName(method + path)
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	// Keep tool names unique when operation IDs collide after sanitizing, skipping suffixed names
+	// that are operation IDs of their own
+	candidate := name
+	for count := 2; g.names[candidate]; count++ {
+		suffix := fmt.Sprintf("_%d", count)
+		candidate = name[:min(len(name), 64-len(suffix))] + suffix
+	}
+	g.names[candidate] = true
+	return candidate
+}
+
+func sanitizeName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+}
+
+func description(operation *object) string {
+	summary := strings.TrimSpace(operation.String("summary"))
+	desc := strings.TrimSpace(operation.String("description"))
+	if summary != "" && desc != "" && summary != desc {
+		return summary + "\n\n" + desc
+	} else if summary != "" {
+		return summary
+	}
+	return desc
+}
+
+// serverURL returns the URL of the first server of the document with its variables substituted
+// by their default values.
+func serverURL(root *object) string {
+	servers, _ := root.Get("servers").([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+	server, ok := servers[0].(*object)
+	if !ok {
+		return ""
+	}
+	serverURL := server.String("url")
+	variables := server.Object("variables")
+	for _, name := range variables.Keys() {
+		value := fmt.Sprint(variables.Object(name).Get("default"))
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", value)
+	}
+	return serverURL
+}
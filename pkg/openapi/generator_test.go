@@ -0,0 +1,390 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testDocument = `
+openapi: 3.0.3
+info:
+  title: Orders
+  version: 1.0.0
+servers:
+  - url: https://{host}/api/v1
+    variables:
+      host:
+        default: orders.example.com
+paths:
+  /orders/{orderId}:
+    parameters:
+      - $ref: "#/components/parameters/OrderId"
+    get:
+      operationId: getOrder
+      summary: Get an order
+      responses:
+        "200":
+          description: The order
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Order"
+      parameters:
+        - name: fields
+          in: query
+          description: Fields to return
+          schema:
+            type: string
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            type: string
+        - name: Accept
+          in: header
+          schema:
+            type: string
+        - name: session
+          in: cookie
+          schema:
+            type: string
+    put:
+      description: Update an order
+      requestBody:
+        required: true
+        content:
+          application/xml:
+            schema:
+              $ref: "#/components/schemas/Order"
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Order"
+  /upload:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          image/png:
+            schema:
+              type: string
+components:
+  parameters:
+    OrderId:
+      name: orderId
+      in: path
+      required: true
+      schema:
+        type: integer
+  schemas:
+    Order:
+      type: object
+      required: [item]
+      properties:
+        item:
+          type: string
+        note:
+          type: string
+          nullable: true
+        parent:
+          $ref: "#/components/schemas/Order"
+`
+
+func TestGenerate(t *testing.T) {
+	tools, err := Generate([]byte(testDocument), Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := []struct {
+		name         string
+		description  string
+		target       string
+		verb         string
+		inputSchema  string
+		outputSchema string
+	}{
+		{
+			name:         "getOrder",
+			description:  "Get an order",
+			target:       "/orders/{orderId}",
+			verb:         "GET",
+			inputSchema:  `{"type":"object","properties":{"path_orderId":{"type":"integer"},"query_fields":{"type":"string","description":"Fields to return"},"header_X-Tenant":{"type":"string"},"cookie_session":{"type":"string"}},"required":["path_orderId","header_X-Tenant"]}`,
+			outputSchema: `{"type":"object","required":["item"],"properties":{"item":{"type":"string"},"note":{"type":["string","null"]},"parent":{"type":"object","description":"Circular reference to #/components/schemas/Order"}}}`,
+		},
+		{
+			name:        "put_orders_orderId",
+			description: "Update an order",
+			target:      "/orders/{orderId}",
+			verb:        "PUT",
+			inputSchema: `{"type":"object","contentType":"application/json","properties":{"path_orderId":{"type":"integer"},"requestBody":{"type":"object","required":["item"],"properties":{"item":{"type":"string"},"note":{"type":["string","null"]},"parent":{"type":"object","description":"Circular reference to #/components/schemas/Order"}}}},"required":["path_orderId","requestBody"]}`,
+		},
+	}
+	if len(tools) != len(want) {
+		t.Fatalf("Generate() returned %d tools, want %d", len(tools), len(want))
+	}
+	for i, tool := range tools {
+		if tool.Name != want[i].name || tool.Description != want[i].description {
+			t.Errorf("tool %d = %s %q, want %s %q", i, tool.Name, tool.Description, want[i].name, want[i].description)
+		}
+		if tool.Backend.Endpoint != "https://orders.example.com/api/v1" || tool.Backend.Target != want[i].target || tool.Backend.Verb != want[i].verb {
+			t.Errorf("tool %s backend = %+v", tool.Name, tool.Backend)
+		}
+		if string(tool.InputSchema) != want[i].inputSchema {
+			t.Errorf("tool %s inputSchema = %s, want %s", tool.Name, tool.InputSchema, want[i].inputSchema)
+		}
+		if string(tool.OutputSchema) != want[i].outputSchema {
+			t.Errorf("tool %s outputSchema = %s, want %s", tool.Name, tool.OutputSchema, want[i].outputSchema)
+		}
+		if !json.Valid(tool.InputSchema) {
+			t.Errorf("tool %s inputSchema is not valid JSON", tool.Name)
+		}
+	}
+}
+
+func TestGenerateEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		opts     Options
+		wantErr  bool
+	}{
+		{
+			name:     "relative server url",
+			document: "openapi: 3.0.0\nservers:\n  - url: /api\npaths: {}\n",
+			wantErr:  true,
+		},
+		{
+			name:     "relative server url with endpoint override",
+			document: "openapi: 3.0.0\nservers:\n  - url: /api\npaths: {}\n",
+			opts:     Options{Endpoint: "http://localhost:9090/api"},
+		},
+		{
+			name:     "swagger 2 document",
+			document: `{"swagger": "2.0", "paths": {}}`,
+			opts:     Options{Endpoint: "http://localhost:9090/api"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate([]byte(tt.document), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateNaturalNames(t *testing.T) {
+	document := `
+openapi: 3.0.3
+servers:
+  - url: https://orders.example.com
+paths:
+  /users/{id}/orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: id
+          in: query
+          schema:
+            type: string
+        - name: status
+          in: query
+          style: pipeDelimited
+          explode: false
+          schema:
+            type: array
+            items:
+              type: string
+`
+	tools, err := Generate([]byte(document), Options{NaturalNames: true})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := `{"type":"object","properties":{"path_id":{"type":"string"},"query_id":{"type":"string"},"status":{"type":"array","items":{"type":"string"},"style":"pipeDelimited","explode":false,"x-mcp-in":"query"}},"required":["path_id"]}`
+	if len(tools) != 1 || string(tools[0].InputSchema) != want {
+		t.Errorf("Generate() inputSchema = %s, want %s", tools[0].InputSchema, want)
+	}
+}
+
+func TestGenerateToolNameCollisions(t *testing.T) {
+	document := `
+openapi: 3.0.3
+servers:
+  - url: https://orders.example.com
+paths:
+  /a:
+    get:
+      operationId: foo
+  /b:
+    get:
+      operationId: foo
+  /c:
+    get:
+      operationId: foo_2
+  /d:
+    get:
+      operationId: foo
+  /e:
+    get:
+      operationId: "??"
+`
+	tools, err := Generate([]byte(document), Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := []string{"foo", "foo_2", "foo_2_2", "foo_3", "get_e"}
+	if len(tools) != len(want) {
+		t.Fatalf("Generate() returned %d tools, want %d", len(tools), len(want))
+	}
+	for i, tool := range tools {
+		if tool.Name != want[i] {
+			t.Errorf("tool %d name = %s, want %s", i, tool.Name, want[i])
+		}
+	}
+}
+
+func TestGenerateOutputSchema(t *testing.T) {
+	document := `
+openapi: 3.0.3
+servers:
+  - url: https://orders.example.com
+paths:
+  /charset:
+    get:
+      operationId: charset
+      responses:
+        "200":
+          content:
+            application/json; charset=utf-8:
+              schema:
+                type: object
+  /suffix:
+    get:
+      operationId: suffix
+      responses:
+        "201":
+          content:
+            text/plain:
+              schema:
+                type: string
+            application/hal+json:
+              schema:
+                type: object
+  /default:
+    get:
+      operationId: default
+      responses:
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+  /notJSON:
+    get:
+      operationId: notJSON
+      responses:
+        "200":
+          content:
+            application/jsonl:
+              schema:
+                type: object
+        default:
+          content:
+            application/json:
+              schema:
+                type: object
+`
+	tools, err := Generate([]byte(document), Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := map[string]string{
+		"charset": `{"type":"object"}`,
+		"suffix":  `{"type":"object"}`,
+		"default": `{"type":"object"}`,
+		"notJSON": "",
+	}
+	if len(tools) != len(want) {
+		t.Fatalf("Generate() returned %d tools, want %d", len(tools), len(want))
+	}
+	for _, tool := range tools {
+		if string(tool.OutputSchema) != want[tool.Name] {
+			t.Errorf("tool %s outputSchema = %s, want %s", tool.Name, tool.OutputSchema, want[tool.Name])
+		}
+	}
+}
+
+func TestGenerateMultipart(t *testing.T) {
+	document := `
+openapi: 3.0.3
+servers:
+  - url: https://files.example.com
+paths:
+  /files:
+    post:
+      operationId: uploadFile
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                title:
+                  type: string
+                file:
+                  type: string
+                  format: binary
+                  description: File to upload
+                checksum:
+                  type: string
+                  format: byte
+`
+	tools, err := Generate([]byte(document), Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := `{"type":"object","contentType":"multipart/form-data","properties":{"requestBody":{"type":"object","properties":{"title":{"type":"string"},"file":{"type":"object","description":"File to upload","properties":{"content":{"type":"string","contentEncoding":"base64","description":"Base64 encoded file content"},"filename":{"type":"string"},"contentType":{"type":"string","description":"Media type of the file"}},"required":["content","filename"]},"checksum":{"type":"string","format":"byte"}}}}}`
+	if len(tools) != 1 || string(tools[0].InputSchema) != want {
+		t.Errorf("Generate() inputSchema = %s, want %s", tools[0].InputSchema, want)
+	}
+}
+
+func TestGenerateXMLRootName(t *testing.T) {
+	document := `
+openapi: 3.0.3
+servers:
+  - url: https://orders.example.com
+paths:
+  /orders:
+    post:
+      operationId: createOrder
+      requestBody:
+        content:
+          application/xml:
+            schema:
+              $ref: "#/components/schemas/Order"
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: integer
+          xml:
+            attribute: true
+`
+	tools, err := Generate([]byte(document), Options{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	want := `{"type":"object","contentType":"application/xml","properties":{"requestBody":{"type":"object","properties":{"id":{"type":"integer","xml":{"attribute":true}}},"xml":{"name":"Order"}}}}`
+	if len(tools) != 1 || string(tools[0].InputSchema) != want {
+		t.Errorf("Generate() inputSchema = %s, want %s", tools[0].InputSchema, want)
+	}
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// object is a JSON object that keeps the order of its keys, so that the generated
+// schemas follow the order of the OpenAPI document.
+type object struct {
+	keys   []string
+	values map[string]any
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]any)}
+}
+
+func (o *object) Get(key string) any {
+	if o == nil {
+		return nil
+	}
+	return o.values[key]
+}
+
+func (o *object) Set(key string, value any) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) Delete(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *object) Keys() []string {
+	if o == nil {
+		return nil
+	}
+	return o.keys
+}
+
+func (o *object) String(key string) string {
+	value, _ := o.Get(key).(string)
+	return value
+}
+
+func (o *object) Bool(key string) bool {
+	value, _ := o.Get(key).(bool)
+	return value
+}
+
+func (o *object) Object(key string) *object {
+	value, _ := o.Get(key).(*object)
+	return value
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fromYAML converts a YAML node into objects, slices and scalars, keeping the order of mapping keys.
+// JSON documents are handled as well since JSON is a subset of YAML.
+func fromYAML(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return fromYAML(node.Content[0])
+	case yaml.MappingNode:
+		obj := newObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := fromYAML(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(node.Content[i].Value, value)
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			item, err := fromYAML(child)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case yaml.AliasNode:
+		return fromYAML(node.Alias)
+	case yaml.ScalarNode:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported YAML node at line %d", node.Line)
+	}
+}
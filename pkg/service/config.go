@@ -2,15 +2,20 @@ package service
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 
 	toml "github.com/pelletier/go-toml/v2"
 )
 
 type Config struct {
-	Server Server `mapstructure:"server"`
-	Http   Http   `mapstructure:"http"`
+	Server  Server  `mapstructure:"server"`
+	Http    Http    `mapstructure:"http"`
+	Session Session `mapstructure:"session"`
+	Tools   Tools   `mapstructure:"tools"`
 }
 
 type Server struct {
@@ -19,23 +24,161 @@ type Server struct {
 	KeyPath  string `mapstructure:"keyPath"`
 	CertPath string `mapstructure:"certPath"`
 	Secure   bool   `mapstructure:"secure"`
+	// OutputMode is the default output of the POST /mcp route, raw or mcp
+	OutputMode string `mapstructure:"outputMode"`
 }
 
+// Output modes of the POST /mcp route
+const (
+	// OutputModeRaw returns the processed body of the underlying API
+	OutputModeRaw = "raw"
+	// OutputModeMCP returns an MCP CallToolResult
+	OutputModeMCP = "mcp"
+)
+
 type Http struct {
 	Insecure        bool `mapstructure:"insecure"`
 	MaxIdleConns    int  `mapstructure:"maxIdleConns"`
 	IdleConnTimeout int  `mapstructure:"idleConnTimeout"`
+	// MaxBinaryResponseSize is the maximum size in bytes of binary responses returned to clients
+	MaxBinaryResponseSize int64 `mapstructure:"maxBinaryResponseSize"`
+	// MaxResponseSize is the maximum size in bytes of text responses, which are truncated past it
+	MaxResponseSize int64 `mapstructure:"maxResponseSize"`
+	// Timeout is the overall timeout in seconds of a call to an underlying API
+	Timeout int `mapstructure:"timeout"`
+	// ConnectTimeout is the timeout in seconds of connecting to an underlying API
+	ConnectTimeout int `mapstructure:"connectTimeout"`
+	// TLSHandshakeTimeout is the timeout in seconds of the TLS handshake
+	TLSHandshakeTimeout int `mapstructure:"tlsHandshakeTimeout"`
+	// ResponseHeaderTimeout is the timeout in seconds of waiting for the response headers
+	ResponseHeaderTimeout int            `mapstructure:"responseHeaderTimeout"`
+	Retry                 Retry          `mapstructure:"retry"`
+	CircuitBreaker        CircuitBreaker `mapstructure:"circuitBreaker"`
+	// TLSProfiles are the TLS configurations of backends by profile name
+	TLSProfiles map[string]TLSProfile `mapstructure:"tlsProfiles"`
+	Proxy       Proxy                 `mapstructure:"proxy"`
+}
+
+// Retry is the retry policy of calls to the underlying APIs. Only idempotent requests are retried.
+type Retry struct {
+	// MaxAttempts is the maximum number of attempts of a request, 1 disables retries
+	MaxAttempts int `mapstructure:"maxAttempts"`
+	// InitialBackoff is the maximum delay in milliseconds before the first retry, which doubles for
+	// each retry
+	InitialBackoff int `mapstructure:"initialBackoff"`
+	// MaxBackoff is the maximum delay in milliseconds before a retry
+	MaxBackoff int `mapstructure:"maxBackoff"`
+	// StatusCodes are the status codes of responses that are retried
+	StatusCodes []int `mapstructure:"statusCodes"`
+}
+
+// CircuitBreaker stops calls to a backend host while too many of its requests fail.
+type CircuitBreaker struct {
+	Enabled bool `mapstructure:"enabled"`
+	// FailureRate is the rate of failed requests in an interval that opens the breaker, between 0 and 1
+	FailureRate float64 `mapstructure:"failureRate"`
+	// MinRequests is the minimum number of requests in an interval before the breaker can open
+	MinRequests int `mapstructure:"minRequests"`
+	// Interval is the period in seconds over which requests are counted
+	Interval int `mapstructure:"interval"`
+	// Cooldown is the time in seconds an open breaker rejects requests before probing the host
+	Cooldown int `mapstructure:"cooldown"`
+	// HalfOpenRequests is the number of probe requests let through at once while half-open, all
+	// of which must succeed to close the breaker
+	HalfOpenRequests int `mapstructure:"halfOpenRequests"`
+}
+
+// TLSProfile is the TLS configuration of connections to a group of backends, selected by the hosts
+// of their endpoints or by the tls_profile option of a tool.
+type TLSProfile struct {
+	// Hosts are the backend hosts using the profile, as host, host:port or *.domain
+	Hosts []string `mapstructure:"hosts"`
+	// CAFile is a PEM bundle of the certificate authorities trusted instead of the system roots
+	CAFile string `mapstructure:"caFile"`
+	// CertFile and KeyFile are the PEM client certificate and key presented for mutual TLS
+	CertFile string `mapstructure:"certFile"`
+	KeyFile  string `mapstructure:"keyFile"`
+	// MinVersion is the minimum TLS version, 1.0 to 1.3
+	MinVersion string `mapstructure:"minVersion"`
+	// ServerName overrides the server name sent for SNI and verified against the server certificate
+	ServerName string `mapstructure:"serverName"`
+	Insecure   bool   `mapstructure:"insecure"`
 }
 
+// Proxy is the outbound proxy of calls to the underlying APIs.
+type Proxy struct {
+	// URL is the proxy of all backends, an http, https or socks5 URL. The HTTP_PROXY, HTTPS_PROXY
+	// and NO_PROXY environment variables are used when it is not set
+	URL string `mapstructure:"url"`
+	// NoProxy are the backend hosts reached directly, as host, host:port or *.domain
+	NoProxy []string `mapstructure:"noProxy"`
+	// Backends override the proxy of groups of backend hosts by name
+	Backends map[string]ProxyBackend `mapstructure:"backends"`
+}
+
+// ProxyBackend is the proxy of a group of backend hosts.
+type ProxyBackend struct {
+	// Hosts are the backend hosts using the proxy, as host, host:port or *.domain
+	Hosts []string `mapstructure:"hosts"`
+	// URL is the proxy of the hosts, an http, https or socks5 URL
+	URL string `mapstructure:"url"`
+	// Direct reaches the hosts without a proxy
+	Direct bool `mapstructure:"direct"`
+}
+
+type Session struct {
+	IdleTimeout       int `mapstructure:"idleTimeout"`
+	KeepAliveInterval int `mapstructure:"keepAliveInterval"`
+}
+
+type Tools struct {
+	Directory string `mapstructure:"directory"`
+}
+
+// Defaults of the http settings that are not configured, which also apply to calls made without a
+// config. Sizes are in bytes, timeouts in seconds and backoffs in milliseconds.
+const (
+	DefaultMaxBinaryResponseSize = 10 * 1024 * 1024
+	DefaultMaxResponseSize       = 1024 * 1024
+	DefaultTimeout               = 60
+	DefaultConnectTimeout        = 10
+	DefaultTLSHandshakeTimeout   = 10
+	DefaultResponseHeaderTimeout = 30
+	DefaultRetryMaxAttempts      = 3
+	DefaultRetryInitialBackoff   = 200
+	DefaultRetryMaxBackoff       = 5000
+	DefaultBreakerFailureRate    = 0.5
+	DefaultBreakerMinRequests    = 10
+	DefaultBreakerInterval       = 60
+	DefaultBreakerCooldown       = 30
+	DefaultBreakerHalfOpen       = 1
+)
+
+// DefaultRetryStatusCodes are the status codes of responses that are retried when they are not
+// configured
+var DefaultRetryStatusCodes = []int{502, 503, 504}
+
+// Defaults in seconds of the session settings that are not configured
+const (
+	DefaultSessionIdleTimeout       = 1800
+	DefaultSessionKeepAliveInterval = 30
+)
+
 var (
 	config     *Config
+	configPath = "config.toml"
 	onceConfig sync.Once
 	errConfig  error
 )
 
+// SetConfigPath sets the path of the config file. Must be called before InitConfig.
+func SetConfigPath(path string) {
+	configPath = path
+}
+
 func InitConfig() (*Config, error) {
 	onceConfig.Do(func() {
-		data, err := os.ReadFile("config.toml")
+		data, err := os.ReadFile(configPath)
 		if err != nil {
 			logger.Error("Failed to read config file", "error", err)
 			errConfig = err
@@ -77,5 +220,123 @@ func validateConfig() error {
 	if config.Server.CertPath == "" {
 		return fmt.Errorf("server cert is not set")
 	}
+	switch config.Server.OutputMode {
+	case "":
+		config.Server.OutputMode = OutputModeRaw
+	case OutputModeRaw, OutputModeMCP:
+	default:
+		return fmt.Errorf("unsupported server output mode: %s", config.Server.OutputMode)
+	}
+	if config.Http.MaxBinaryResponseSize <= 0 {
+		config.Http.MaxBinaryResponseSize = DefaultMaxBinaryResponseSize
+	}
+	if config.Http.MaxResponseSize <= 0 {
+		config.Http.MaxResponseSize = DefaultMaxResponseSize
+	}
+	if config.Http.Timeout <= 0 {
+		config.Http.Timeout = DefaultTimeout
+	}
+	if config.Http.ConnectTimeout <= 0 {
+		config.Http.ConnectTimeout = DefaultConnectTimeout
+	}
+	if config.Http.TLSHandshakeTimeout <= 0 {
+		config.Http.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if config.Http.ResponseHeaderTimeout <= 0 {
+		config.Http.ResponseHeaderTimeout = DefaultResponseHeaderTimeout
+	}
+	if config.Http.Retry.MaxAttempts <= 0 {
+		config.Http.Retry.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if config.Http.Retry.InitialBackoff <= 0 {
+		config.Http.Retry.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if config.Http.Retry.MaxBackoff <= 0 {
+		config.Http.Retry.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if config.Http.Retry.StatusCodes == nil {
+		config.Http.Retry.StatusCodes = slices.Clone(DefaultRetryStatusCodes)
+	}
+	if config.Http.CircuitBreaker.FailureRate <= 0 {
+		config.Http.CircuitBreaker.FailureRate = DefaultBreakerFailureRate
+	} else if config.Http.CircuitBreaker.FailureRate > 1 {
+		return fmt.Errorf("circuit breaker failure rate must be between 0 and 1")
+	}
+	if config.Http.CircuitBreaker.MinRequests <= 0 {
+		config.Http.CircuitBreaker.MinRequests = DefaultBreakerMinRequests
+	}
+	if config.Http.CircuitBreaker.Interval <= 0 {
+		config.Http.CircuitBreaker.Interval = DefaultBreakerInterval
+	}
+	if config.Http.CircuitBreaker.Cooldown <= 0 {
+		config.Http.CircuitBreaker.Cooldown = DefaultBreakerCooldown
+	}
+	if config.Http.CircuitBreaker.HalfOpenRequests <= 0 {
+		config.Http.CircuitBreaker.HalfOpenRequests = DefaultBreakerHalfOpen
+	}
+	profileHosts := make(map[string]string)
+	for name, profile := range config.Http.TLSProfiles {
+		switch profile.MinVersion {
+		case "", "1.0", "1.1", "1.2", "1.3":
+		default:
+			return fmt.Errorf("unsupported minimum TLS version of tls profile %s: %s", name, profile.MinVersion)
+		}
+		if (profile.CertFile == "") != (profile.KeyFile == "") {
+			return fmt.Errorf("tls profile %s requires both certFile and keyFile", name)
+		}
+		for _, host := range profile.Hosts {
+			host = strings.ToLower(host)
+			if other, exists := profileHosts[host]; exists {
+				return fmt.Errorf("host %s is in tls profiles %s and %s", host, other, name)
+			}
+			profileHosts[host] = name
+		}
+	}
+	if err := validateProxyURL(config.Http.Proxy.URL); err != nil {
+		return fmt.Errorf("invalid proxy url: %v", err)
+	}
+	proxyHosts := make(map[string]string)
+	for name, backend := range config.Http.Proxy.Backends {
+		if backend.Direct == (backend.URL != "") {
+			return fmt.Errorf("proxy backend %s requires either url or direct", name)
+		}
+		if err := validateProxyURL(backend.URL); err != nil {
+			return fmt.Errorf("invalid url of proxy backend %s: %v", name, err)
+		}
+		for _, host := range backend.Hosts {
+			host = strings.ToLower(host)
+			if other, exists := proxyHosts[host]; exists {
+				return fmt.Errorf("host %s is in proxy backends %s and %s", host, other, name)
+			}
+			proxyHosts[host] = name
+		}
+	}
+	if config.Session.IdleTimeout <= 0 {
+		config.Session.IdleTimeout = DefaultSessionIdleTimeout
+	}
+	if config.Session.KeepAliveInterval <= 0 {
+		config.Session.KeepAliveInterval = DefaultSessionKeepAliveInterval
+	}
+	return nil
+}
+
+// validateProxyURL checks that a proxy URL has a supported scheme and a host.
+func validateProxyURL(proxyURL string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		// The error quotes the URL, which may carry credentials
+		return fmt.Errorf("malformed proxy url")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme: %s", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("proxy url %s has no host", u.Redacted())
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 	"sync"
@@ -14,15 +15,30 @@ type LogHandler struct {
 type contextKey string
 
 const (
-	ToolNameKey contextKey = "toolName"
-	ApiNameKey  contextKey = "apiName"
+	ToolNameKey  contextKey = "toolName"
+	ApiNameKey   contextKey = "apiName"
+	SessionIDKey contextKey = "sessionId"
 )
 
+// logOutput allows the log destination to be changed after the logger is created
+type logOutput struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (o *logOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.writer.Write(p)
+}
+
+var output = &logOutput{writer: os.Stdout}
+
 var syncOnceLogger sync.Once
 
 var logger *slog.Logger
 
-// Custom log handler to add toolName and apiName attributes to each log
+// Custom log handler to add toolName, apiName and sessionId attributes to each log
 func (l *LogHandler) Handle(ctx context.Context, r slog.Record) error {
 	if toolName, ok := ctx.Value(ToolNameKey).(string); ok {
 		r.AddAttrs(slog.String("toolName", toolName))
@@ -30,6 +46,9 @@ func (l *LogHandler) Handle(ctx context.Context, r slog.Record) error {
 	if apiName, ok := ctx.Value(ApiNameKey).(string); ok {
 		r.AddAttrs(slog.String("apiName", apiName))
 	}
+	if sessionID, ok := ctx.Value(SessionIDKey).(string); ok {
+		r.AddAttrs(slog.String("sessionId", sessionID))
+	}
 	return l.Handler.Handle(ctx, r)
 }
 
@@ -37,10 +56,17 @@ func GetLogger() *slog.Logger {
 	syncOnceLogger.Do(func() {
 		if logger == nil {
 			attributes := []slog.Attr{}
-			baseHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{AddSource: false}).WithAttrs(attributes)
+			baseHandler := slog.NewJSONHandler(output, &slog.HandlerOptions{AddSource: false}).WithAttrs(attributes)
 			customHandler := &LogHandler{Handler: baseHandler}
 			logger = slog.New(customHandler)
 		}
 	})
 	return logger
 }
+
+// SetLogOutput redirects the logs to the given writer.
+func SetLogOutput(writer io.Writer) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.writer = writer
+}
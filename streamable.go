@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	mcp "mcp-server/pkg/mcp"
+	"mcp-server/pkg/service"
+)
+
+var sessionStore *mcp.SessionStore
+
+var keepAliveInterval time.Duration
+
+// registerStreamableHTTP registers the MCP Streamable HTTP transport on the given path.
+func registerStreamableHTTP(router *gin.Engine, path string, cfg *service.Config) {
+	sessionStore = mcp.NewSessionStore(time.Duration(cfg.Session.IdleTimeout) * time.Second)
+	sessionStore.StartExpiry(context.Background(), time.Minute)
+	keepAliveInterval = time.Duration(cfg.Session.KeepAliveInterval) * time.Second
+
+	router.POST(path, handleStreamablePost)
+	router.GET(path, handleStreamableGet)
+	router.DELETE(path, handleStreamableDelete)
+}
+
+// handleStreamablePost handles JSON-RPC messages sent by the client.
+// A new session is created when the client sends an initialize request.
+func handleStreamablePost(c *gin.Context) {
+	message, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		logger.Error("Failed to read JSON-RPC message", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	ctx := c.Request.Context()
+	var request mcp.JSONRPCRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		c.JSON(http.StatusBadRequest, mcpServer.HandleMessage(ctx, message))
+		return
+	}
+
+	if request.Method == mcp.MethodInitialize {
+		session, err := sessionStore.Create()
+		if err != nil {
+			logger.Error("Failed to create session", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+			return
+		}
+		ctx = context.WithValue(mcp.ContextWithSession(ctx, session), service.SessionIDKey, session.ID)
+		response := mcpServer.HandleMessage(ctx, message)
+		if response == nil || response.Error != nil {
+			sessionStore.Delete(session.ID)
+		} else {
+			logger.InfoContext(ctx, "Created MCP session")
+			c.Header(mcp.HeaderSessionID, session.ID)
+		}
+		writeJSONRPCResponse(c, response)
+		return
+	}
+
+	session, ok := lookupSession(c)
+	if !ok {
+		return
+	}
+	ctx = context.WithValue(mcp.ContextWithSession(ctx, session), service.SessionIDKey, session.ID)
+	// Responses from the client are accepted but not processed since the server does not send requests
+	if request.Method == "" && request.ID != nil {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	writeJSONRPCResponse(c, mcpServer.HandleMessage(ctx, message))
+}
+
+// handleStreamableGet opens an SSE stream for server initiated messages of a session.
+func handleStreamableGet(c *gin.Context) {
+	if !strings.Contains(c.GetHeader("Accept"), mcp.ContentTypeSSE) {
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": "Client must accept text/event-stream"})
+		return
+	}
+	session, ok := lookupSession(c)
+	if !ok {
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), service.SessionIDKey, session.ID)
+	logger.InfoContext(ctx, "Opened SSE stream")
+
+	c.Header(mcp.ContentType, mcp.ContentTypeSSE)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.InfoContext(ctx, "Client closed SSE stream")
+			return
+		case <-session.Done():
+			logger.InfoContext(ctx, "Session terminated, closing SSE stream")
+			return
+		case message := <-session.Messages():
+			fmt.Fprintf(c.Writer, "event: message\ndata: %s\n\n", message)
+			c.Writer.Flush()
+		case <-ticker.C:
+			// Keep the connection and the session alive while the stream is open
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+			session.Touch()
+		}
+	}
+}
+
+// handleStreamableDelete terminates a session on the client's request.
+func handleStreamableDelete(c *gin.Context) {
+	session, ok := lookupSession(c)
+	if !ok {
+		return
+	}
+	sessionStore.Delete(session.ID)
+	ctx := context.WithValue(c.Request.Context(), service.SessionIDKey, session.ID)
+	logger.InfoContext(ctx, "Terminated MCP session")
+	c.Status(http.StatusNoContent)
+}
+
+// lookupSession returns the session identified by the Mcp-Session-Id header.
+// Writes the error response and returns false if the session is missing or unknown.
+func lookupSession(c *gin.Context) (*mcp.Session, bool) {
+	if version := c.GetHeader(mcp.HeaderProtocolVersion); version != "" && !slices.Contains(mcp.SupportedProtocolVersions, version) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported protocol version: %s", version)})
+		return nil, false
+	}
+	sessionID := c.GetHeader(mcp.HeaderSessionID)
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session ID is required"})
+		return nil, false
+	}
+	session, ok := sessionStore.Get(sessionID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return nil, false
+	}
+	return session, true
+}
+
+func writeJSONRPCResponse(c *gin.Context, response *mcp.JSONRPCResponse) {
+	if response == nil {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
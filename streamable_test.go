@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	mcp "mcp-server/pkg/mcp"
+	"mcp-server/pkg/service"
+)
+
+const initializeMessage = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"client","version":"1.0"}}}`
+
+// newStreamableServer starts a server serving the Streamable HTTP transport on /mcp/jsonrpc.
+func newStreamableServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mcpServer = mcp.NewServer("test-server", "0.1", nil)
+	cfg := &service.Config{Session: service.Session{IdleTimeout: 60, KeepAliveInterval: 30}}
+	registerStreamableHTTP(router, "/mcp/jsonrpc", cfg)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sendStreamable sends a request to the transport with the given session ID, if any.
+func sendStreamable(t *testing.T, server *httptest.Server, method string, sessionID string, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+"/mcp/jsonrpc", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(mcp.ContentType, mcp.ContentTypeJSON)
+	if sessionID != "" {
+		req.Header.Set(mcp.HeaderSessionID, sessionID)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// initializeSession initializes a session and returns its ID.
+func initializeSession(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	resp := sendStreamable(t, server, http.MethodPost, "", initializeMessage)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	sessionID := resp.Header.Get(mcp.HeaderSessionID)
+	if sessionID == "" {
+		t.Fatalf("initialize response has no %s header", mcp.HeaderSessionID)
+	}
+	return sessionID
+}
+
+func TestStreamableInitialize(t *testing.T) {
+	server := newStreamableServer(t)
+	sessionID := initializeSession(t, server)
+	if _, ok := sessionStore.Get(sessionID); !ok {
+		t.Errorf("session %s was not stored", sessionID)
+	}
+
+	// A failed initialization does not create a session
+	resp := sendStreamable(t, server, http.MethodPost, "", `{"jsonrpc":"1.0","id":1,"method":"initialize"}`)
+	if sessionID := resp.Header.Get(mcp.HeaderSessionID); sessionID != "" {
+		t.Errorf("failed initialize response has the %s header %s", mcp.HeaderSessionID, sessionID)
+	}
+	if sessionStore.Len() != 1 {
+		t.Errorf("session store has %d sessions, want 1", sessionStore.Len())
+	}
+}
+
+func TestStreamablePost(t *testing.T) {
+	server := newStreamableServer(t)
+	sessionID := initializeSession(t, server)
+	tests := []struct {
+		name       string
+		sessionID  string
+		version    string
+		message    string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "request",
+			sessionID:  sessionID,
+			message:    `{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"jsonrpc":"2.0","id":2,"result":{}}`,
+		},
+		{
+			name:       "notification",
+			sessionID:  sessionID,
+			message:    `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "client response",
+			sessionID:  sessionID,
+			message:    `{"jsonrpc":"2.0","id":"server-1","result":{}}`,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "missing session ID",
+			message:    `{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Session ID is required"}`,
+		},
+		{
+			name:       "unknown session ID",
+			sessionID:  "unknown",
+			message:    `{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"Session not found"}`,
+		},
+		{
+			name:       "unsupported protocol version",
+			sessionID:  sessionID,
+			version:    "1999-01-01",
+			message:    `{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"Unsupported protocol version: 1999-01-01"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/mcp/jsonrpc", strings.NewReader(tt.message))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.sessionID != "" {
+				req.Header.Set(mcp.HeaderSessionID, tt.sessionID)
+			}
+			if tt.version != "" {
+				req.Header.Set(mcp.HeaderProtocolVersion, tt.version)
+			}
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStreamableDelete(t *testing.T) {
+	server := newStreamableServer(t)
+	sessionID := initializeSession(t, server)
+
+	if resp := sendStreamable(t, server, http.MethodDelete, sessionID, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	if _, ok := sessionStore.Get(sessionID); ok {
+		t.Errorf("session %s was not deleted", sessionID)
+	}
+	// The session ID is unknown once the session is terminated
+	if resp := sendStreamable(t, server, http.MethodPost, sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST after DELETE status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if resp := sendStreamable(t, server, http.MethodDelete, sessionID, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("second DELETE status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if resp := sendStreamable(t, server, http.MethodDelete, "", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("DELETE without session ID status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestStreamableGet(t *testing.T) {
+	server := newStreamableServer(t)
+	sessionID := initializeSession(t, server)
+
+	if resp := sendStreamable(t, server, http.MethodGet, sessionID, ""); resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("GET without accepting SSE status = %d, want %d", resp.StatusCode, http.StatusNotAcceptable)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/mcp/jsonrpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", mcp.ContentTypeSSE)
+	req.Header.Set(mcp.HeaderSessionID, sessionID)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if contentType := resp.Header.Get(mcp.ContentType); contentType != mcp.ContentTypeSSE {
+		t.Errorf("GET content type = %s, want %s", contentType, mcp.ContentTypeSSE)
+	}
+
+	session, _ := sessionStore.Get(sessionID)
+	if !session.Send([]byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)) {
+		t.Fatal("failed to send a message to the session")
+	}
+	reader := bufio.NewReader(resp.Body)
+	var event string
+	for !strings.HasSuffix(event, "\n\n") {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the SSE stream: %v", err)
+		}
+		event += line
+	}
+	want := "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/tools/list_changed\"}\n\n"
+	if event != want {
+		t.Errorf("SSE event = %q, want %q", event, want)
+	}
+
+	// Terminating the session closes the stream
+	sessionStore.Delete(sessionID)
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("SSE stream was not closed: %v", err)
+	}
+}